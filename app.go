@@ -7,12 +7,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	goruntime "runtime"
+	"strings"
 	"sync"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 
 	"syncLauperVideoConverter/internal/encoder"
 	"syncLauperVideoConverter/internal/fileinfo"
+	"syncLauperVideoConverter/internal/history"
 	"syncLauperVideoConverter/internal/preset"
 )
 
@@ -34,14 +36,16 @@ type App struct {
 	encoder   *encoder.Encoder
 	files     []*fileinfo.FileInfo
 	outputDir string
+	fileOpts  map[string]encoder.JobOptions // per-file options by path
 	mu        sync.RWMutex
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
 	return &App{
-		encoder: encoder.NewEncoder(),
-		files:   make([]*fileinfo.FileInfo, 0),
+		encoder:  encoder.NewEncoder(),
+		files:    make([]*fileinfo.FileInfo, 0),
+		fileOpts: make(map[string]encoder.JobOptions),
 	}
 }
 
@@ -62,14 +66,24 @@ func (a *App) startup(ctx context.Context) {
 	})
 
 	a.encoder.SetCompleteCallback(func(result *encoder.EncodeResult, job *encoder.EncodingJob) {
+		a.recordHistory(job)
 		runtime.EventsEmit(a.ctx, "encoding:fileComplete", map[string]interface{}{
-			"success":    result.Success,
-			"outputPath": result.OutputPath,
-			"filename":   job.FileInfo.Name,
+			"success":         result.Success,
+			"outputPath":      result.OutputPath,
+			"filename":        job.FileInfo.Name,
+			"bitrateCheck":    job.BitrateCheck,
+			"loudness":        job.Loudness,
+			"frameCheck":      job.FrameCheck,
+			"calibrationPath": job.CalibrationPath,
+			"loopCheck":       job.LoopCheck,
+			"loopPath":        job.LoopPath,
+			"loopPathCheck":   job.LoopPathCheck,
+			"sha256":          job.SHA256,
 		})
 	})
 
 	a.encoder.SetErrorCallback(func(err error, job *encoder.EncodingJob) {
+		a.recordHistory(job)
 		runtime.EventsEmit(a.ctx, "encoding:error", map[string]interface{}{
 			"error":    err.Error(),
 			"filename": job.FileInfo.Name,
@@ -77,9 +91,19 @@ func (a *App) startup(ctx context.Context) {
 	})
 
 	a.encoder.SetAllCompleteCallback(func(completed int, failed int) {
+		// Keyframe alignment is checked across the whole batch, so it is reported here
+		keyframeChecks := make(map[string]*encoder.KeyframeCheck)
+		for _, job := range a.encoder.GetJobs() {
+			if job.KeyframeCheck != nil {
+				keyframeChecks[job.FileInfo.Name] = job.KeyframeCheck
+			}
+		}
+
 		runtime.EventsEmit(a.ctx, "encoding:allComplete", map[string]interface{}{
-			"completed": completed,
-			"failed":    failed,
+			"completed":      completed,
+			"failed":         failed,
+			"keyframeChecks": keyframeChecks,
+			"manifestPaths":  a.encoder.GetManifestPaths(),
 		})
 	})
 }
@@ -198,6 +222,17 @@ func (a *App) LoadAllMetadata() []*fileinfo.FileInfo {
 			r.target.Framerate = r.info.Framerate
 			r.target.Codec = r.info.Codec
 			r.target.AudioCodec = r.info.AudioCodec
+			r.target.AudioTracks = r.info.AudioTracks
+			r.target.AudioTracksKnown = r.info.AudioTracksKnown
+			r.target.BitDepth = r.info.BitDepth
+			r.target.ColorPrimaries = r.info.ColorPrimaries
+			r.target.ColorTransfer = r.info.ColorTransfer
+			r.target.ColorSpace = r.info.ColorSpace
+			r.target.ColorRange = r.info.ColorRange
+			r.target.MasterDisplay = r.info.MasterDisplay
+			r.target.MaxCLL = r.info.MaxCLL
+			r.target.MaxFALL = r.info.MaxFALL
+			r.target.AudioSkewMs = r.info.AudioSkewMs
 		}
 		a.mu.Unlock()
 	}
@@ -226,6 +261,7 @@ func (a *App) RemoveFile(path string) bool {
 	for i, f := range a.files {
 		if f.Path == path {
 			a.files = append(a.files[:i], a.files[i+1:]...)
+			delete(a.fileOpts, path)
 			return true
 		}
 	}
@@ -237,6 +273,7 @@ func (a *App) ClearFiles() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.files = make([]*fileinfo.FileInfo, 0)
+	a.fileOpts = make(map[string]encoder.JobOptions)
 }
 
 // GetFiles returns all added files
@@ -305,6 +342,10 @@ func (a *App) StartEncoding(presetName string) error {
 	a.mu.RLock()
 	files := a.files
 	outputDir := a.outputDir
+	fileOpts := make(map[string]encoder.JobOptions, len(a.fileOpts))
+	for path, opts := range a.fileOpts {
+		fileOpts[path] = opts
+	}
 	a.mu.RUnlock()
 
 	if len(files) == 0 {
@@ -317,16 +358,42 @@ func (a *App) StartEncoding(presetName string) error {
 		return fmt.Errorf("프리셋을 찾을 수 없습니다: %s", presetName)
 	}
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("출력 폴더를 생성할 수 없습니다: %v", err)
+	// Re-queued files keep their own preset, settings and output folder. Combinations
+	// the encoder can't handle are refused before any job starts.
+	filePresets := make(map[string]*preset.Preset, len(files))
+	fileDirs := make(map[string]string, len(files))
+	for _, file := range files {
+		opts := fileOpts[file.Path]
+		filePreset, fileDir := p, outputDir
+		if opts.Preset != "" {
+			if filePreset = preset.GetPresetByName(opts.Preset); filePreset == nil {
+				return fmt.Errorf("프리셋을 찾을 수 없습니다: %s", opts.Preset)
+			}
+		}
+		if opts.OutputDir != "" {
+			fileDir = opts.OutputDir
+		}
+
+		settings := a.encoder.JobSettings(opts)
+		if err := a.encoder.CheckEncoderSupport(filePreset, settings, file); err != nil {
+			return err
+		}
+		if err := a.encoder.CheckLoopSupport(filePreset, settings, file, opts); err != nil {
+			return err
+		}
+
+		// Create output directory if it doesn't exist
+		if err := os.MkdirAll(fileDir, 0755); err != nil {
+			return fmt.Errorf("출력 폴더를 생성할 수 없습니다: %v", err)
+		}
+		filePresets[file.Path], fileDirs[file.Path] = filePreset, fileDir
 	}
 
 	// Clear previous jobs and add new ones
 	a.encoder.ClearJobs()
 
 	for _, file := range files {
-		_, err := a.encoder.AddJob(file.Path, outputDir, p)
+		_, err := a.encoder.AddJob(file.Path, fileDirs[file.Path], filePresets[file.Path], fileOpts[file.Path])
 		if err != nil {
 			runtime.EventsEmit(a.ctx, "encoding:error", map[string]interface{}{
 				"error":    err.Error(),
@@ -374,6 +441,11 @@ func (a *App) GetAvailableEncoders() []encoder.HWEncoder {
 	return a.encoder.GetAvailableEncoders()
 }
 
+// RedetectEncoders forces a fresh hardware encoder detection, ignoring the cached results
+func (a *App) RedetectEncoders() []encoder.HWEncoder {
+	return a.encoder.RedetectEncoders()
+}
+
 // SetEncoder sets the encoder to use for encoding
 func (a *App) SetEncoder(encoderID string) {
 	a.encoder.SetEncoder(encoderID)
@@ -394,9 +466,366 @@ func (a *App) SetQuality(crf int) {
 	a.encoder.SetQuality(crf)
 }
 
-// SetBlackIntroDuration sets the black intro duration in seconds (0 = disabled)
-func (a *App) SetBlackIntroDuration(seconds int) {
-	a.encoder.SetBlackIntroDuration(seconds)
+// SetBitDepth sets the output bit depth (8 or 10; 0 = use the preset)
+func (a *App) SetBitDepth(bits int) {
+	a.encoder.SetBitDepth(bits)
+}
+
+// SetHDRMode sets how HDR sources are handled ("tonemap", "preserve", or "" to use the preset)
+func (a *App) SetHDRMode(mode string) {
+	a.encoder.SetHDRMode(mode)
+}
+
+// SetToneMap sets the HDR to SDR tonemap algorithm ("hable", "mobius", "reinhard")
+func (a *App) SetToneMap(algorithm string) {
+	a.encoder.SetToneMap(algorithm)
+}
+
+// SetAudioOptions overrides the preset audio mode, channel layout, bitrate and source track
+func (a *App) SetAudioOptions(opts preset.AudioOptions) {
+	a.encoder.SetAudioOptions(&opts)
+}
+
+// ResetAudioOptions returns to the audio options of the selected preset
+func (a *App) ResetAudioOptions() {
+	a.encoder.SetAudioOptions(nil)
+}
+
+// SetLoudnessTarget enables EBU R128 loudness normalization to the given LUFS, e.g., -23 or -16 (0 = disabled)
+func (a *App) SetLoudnessTarget(lufs float64) {
+	a.encoder.SetLoudnessTarget(lufs)
+}
+
+// SetFileAudioOffset shifts the audio of one file in milliseconds (+ = audio later, 0 = none)
+func (a *App) SetFileAudioOffset(path string, ms int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	opts := a.fileOpts[path]
+	opts.AudioOffsetMs = ms
+	a.setFileOptions(path, opts)
+}
+
+// SetFileScreenLabel sets the screen label burned into the calibration output of one file
+func (a *App) SetFileScreenLabel(path string, label string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	opts := a.fileOpts[path]
+	opts.ScreenLabel = label
+	a.setFileOptions(path, opts)
+}
+
+// AnalyzeSyncOffsets finds the start offset of every file against the reference file
+// by cross-correlating their audio
+func (a *App) AnalyzeSyncOffsets(referencePath string) ([]encoder.SyncOffset, error) {
+	a.mu.RLock()
+	files := make([]*fileinfo.FileInfo, len(a.files))
+	copy(files, a.files)
+	a.mu.RUnlock()
+
+	if len(files) < 2 {
+		return nil, fmt.Errorf("비교할 파일이 2개 이상 필요합니다")
+	}
+	return a.encoder.AnalyzeSyncOffsets(referencePath, files)
+}
+
+// ApplySyncOffsets trims the start of each file so all outputs begin on the same moment
+func (a *App) ApplySyncOffsets(offsets []encoder.SyncOffset) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for path, trim := range encoder.SyncTrims(offsets) {
+		opts := a.fileOpts[path]
+		opts.TrimStart = trim
+		a.setFileOptions(path, opts)
+	}
+}
+
+// DetectSyncFlashes finds the sync flash (a sharp rise in mean luma) in the first
+// seconds of every file, for sources without usable audio (0 = default window)
+func (a *App) DetectSyncFlashes(seconds float64) ([]encoder.FlashSync, error) {
+	a.mu.RLock()
+	files := make([]*fileinfo.FileInfo, len(a.files))
+	copy(files, a.files)
+	a.mu.RUnlock()
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("분석할 파일이 없습니다")
+	}
+	return a.encoder.DetectSyncFlashes(files, seconds)
+}
+
+// ApplyFlashSync trims the start of each file so the flash lands on the same frame in all outputs
+func (a *App) ApplyFlashSync(flashes []encoder.FlashSync) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for path, trim := range encoder.FlashTrims(flashes) {
+		opts := a.fileOpts[path]
+		opts.TrimStart = trim
+		a.setFileOptions(path, opts)
+	}
+}
+
+// SetFileTrim sets the in and out points of one file. Points are timecode
+// ("HH:MM:SS:FF"), time ("HH:MM:SS.mmm"), frames ("120f") or seconds ("12.5"); "" = none.
+func (a *App) SetFileTrim(path string, in string, out string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var file *fileinfo.FileInfo
+	for _, f := range a.files {
+		if f.Path == path {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return fmt.Errorf("파일을 찾을 수 없습니다: %s", path)
+	}
+
+	inPoint, err := encoder.ParsePosition(in, file.Framerate)
+	if err != nil {
+		return err
+	}
+	outPoint, err := encoder.ParsePosition(out, file.Framerate)
+	if err != nil {
+		return err
+	}
+	if outPoint > 0 && outPoint <= inPoint {
+		return fmt.Errorf("아웃 포인트는 인 포인트보다 뒤여야 합니다")
+	}
+	if file.DurationSeconds > 0 && inPoint >= file.DurationSeconds {
+		return fmt.Errorf("인 포인트가 파일 길이를 넘습니다")
+	}
+
+	opts := a.fileOpts[path]
+	opts.InPoint = inPoint
+	opts.OutPoint = outPoint
+	a.setFileOptions(path, opts)
+	return nil
+}
+
+// setFileOptions stores the options of one file and updates its trimmed length.
+// Callers must hold a.mu.
+func (a *App) setFileOptions(path string, opts encoder.JobOptions) {
+	a.fileOpts[path] = opts
+	for _, f := range a.files {
+		if f.Path == path {
+			f.TrimmedSeconds = 0
+			if opts.Trimmed() {
+				f.TrimmedSeconds = opts.TrimmedDuration(f.DurationSeconds)
+			}
+		}
+	}
+}
+
+// GetFileOptions returns the per-file options of a file
+func (a *App) GetFileOptions(path string) encoder.JobOptions {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.fileOpts[path]
+}
+
+// SetHWDecode enables or disables hardware decoding and scaling for hardware encoders
+func (a *App) SetHWDecode(enabled bool) {
+	a.encoder.SetHWDecode(enabled)
+}
+
+// SetBlackIntroDuration sets the black intro duration in seconds, fractions allowed (0 = disabled)
+func (a *App) SetBlackIntroDuration(seconds float64) {
+	a.encoder.SetPreRoll(preset.Roll{Kind: preset.RollBlack, Duration: preset.RollDuration{Seconds: seconds}})
+}
+
+// SetBlackIntroFrames sets the black intro length in frames at the output framerate (0 = disabled)
+func (a *App) SetBlackIntroFrames(frames int) {
+	a.encoder.SetPreRoll(preset.Roll{Kind: preset.RollBlack, Duration: preset.RollDuration{Frames: frames}})
+}
+
+// SetBlackOutroDuration sets the black outro duration in seconds, fractions allowed (0 = disabled)
+func (a *App) SetBlackOutroDuration(seconds float64) {
+	a.encoder.SetPostRoll(preset.Roll{Kind: preset.RollBlack, Duration: preset.RollDuration{Seconds: seconds}})
+}
+
+// SetBlackOutroFrames sets the black outro length in frames at the output framerate (0 = disabled)
+func (a *App) SetBlackOutroFrames(frames int) {
+	a.encoder.SetPostRoll(preset.Roll{Kind: preset.RollBlack, Duration: preset.RollDuration{Frames: frames}})
+}
+
+// SetLoopOptions enables loop seam verification and the seamless loop file
+// (crossfade in seconds between the tail and the head, 0 = no loop file)
+func (a *App) SetLoopOptions(opts encoder.LoopOptions) {
+	a.encoder.SetLoopOptions(opts)
+}
+
+// SetCalibrationOverlay enables a second output per file with burned-in timecode,
+// frame number, filename and screen label
+func (a *App) SetCalibrationOverlay(enabled bool) {
+	a.encoder.SetCalibrationOverlay(enabled)
+}
+
+// GenerateTestPattern writes synchronized test files for a multi-screen rig into the
+// output folder, one per screen, using the given preset and the selected encoder
+func (a *App) GenerateTestPattern(presetName string, opts encoder.TestPatternOptions) ([]string, error) {
+	p := preset.GetPresetByName(presetName)
+	if p == nil {
+		return nil, fmt.Errorf("프리셋을 찾을 수 없습니다: %s", presetName)
+	}
+	if opts.Screens <= 0 {
+		return nil, fmt.Errorf("화면 수는 1 이상이어야 합니다")
+	}
+
+	a.mu.RLock()
+	outputDir := a.outputDir
+	a.mu.RUnlock()
+
+	return a.encoder.GenerateTestPattern(p, outputDir, opts)
+}
+
+// SetPreRoll sets a black, image (slate, logo) or clip (countdown) segment before each file
+func (a *App) SetPreRoll(roll preset.Roll) error {
+	return a.encoder.SetPreRoll(roll)
+}
+
+// SetPostRoll sets a black, image or clip segment after each file
+func (a *App) SetPostRoll(roll preset.Roll) error {
+	return a.encoder.SetPostRoll(roll)
+}
+
+// OpenRollFileDialog opens a file selection dialog for a pre/post-roll image or clip
+func (a *App) OpenRollFileDialog() (string, error) {
+	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "삽입할 이미지 또는 클립 선택",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "이미지 및 비디오 파일",
+				Pattern:     "*.png;*.jpg;*.jpeg;*.tif;*.tiff;*.bmp;*.mp4;*.mov;*.mkv;*.webm;*.m4v;*.avi",
+			},
+			{
+				DisplayName: "모든 파일",
+				Pattern:     "*.*",
+			},
+		},
+	})
+}
+
+// SetFades sets the video and audio fade in/out durations in seconds (0 = no fade)
+func (a *App) SetFades(fades preset.FadeOptions) {
+	a.encoder.SetFades(fades)
+}
+
+// SelectVerifyFolder opens a folder selection dialog for checksum verification
+func (a *App) SelectVerifyFolder() string {
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "검증할 폴더 선택",
+	})
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// VerifyFolder re-hashes copied outputs in a folder against their recorded checksums
+func (a *App) VerifyFolder(dir string) ([]encoder.ChecksumResult, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("폴더를 선택해주세요")
+	}
+	return encoder.VerifyFolder(dir)
+}
+
+// ExportReport saves the report of the last batch as "json", "csv" or "html"
+func (a *App) ExportReport(format string) (string, error) {
+	report := a.encoder.GetReport()
+	if report == nil {
+		return "", fmt.Errorf("내보낼 인코딩 보고서가 없습니다")
+	}
+
+	a.mu.RLock()
+	outputDir := a.outputDir
+	a.mu.RUnlock()
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:            "인코딩 보고서 저장",
+		DefaultDirectory: outputDir,
+		DefaultFilename:  encoder.ReportFilename(report, format),
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: strings.ToUpper(format),
+				Pattern:     "*." + format,
+			},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	if err := encoder.ExportReport(report, path, format); err != nil {
+		return "", fmt.Errorf("보고서 저장 실패: %v", err)
+	}
+	return path, nil
+}
+
+// recordHistory appends a finished job to the encode history
+func (a *App) recordHistory(job *encoder.EncodingJob) {
+	// Record the settings the job ran with; a job that never started gets the ones it would have used
+	settings := a.encoder.JobSettings(job.Options)
+	if job.EncoderSettings != nil {
+		settings = *job.EncoderSettings
+	}
+	if err := history.Append(history.NewEntry(job, settings)); err != nil {
+		fmt.Printf("[History] %s: not recorded: %v\n", job.FileInfo.Name, err)
+	}
+}
+
+// SearchHistory returns past jobs matching the filter, newest first
+func (a *App) SearchHistory(filter history.Filter) ([]history.Entry, error) {
+	return history.Search(filter)
+}
+
+// RequeueHistory adds the source of a past job back to the file list. The job's
+// encoder settings, preset and output folder are kept with its per-file options,
+// so the rest of the batch and the current settings are left unchanged.
+func (a *App) RequeueHistory(id string) (*history.Entry, error) {
+	if a.encoder.IsRunning() {
+		return nil, fmt.Errorf("인코딩 중에는 다시 추가할 수 없습니다")
+	}
+
+	entry, err := history.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	if preset.GetPresetByName(entry.Preset) == nil {
+		return nil, fmt.Errorf("프리셋을 찾을 수 없습니다: %s", entry.Preset)
+	}
+	if _, err := os.Stat(entry.Source); err != nil {
+		return nil, fmt.Errorf("원본 파일을 찾을 수 없습니다: %s", entry.Source)
+	}
+	// Replacing the options of a listed file would silently change how it encodes
+	a.mu.RLock()
+	for _, f := range a.files {
+		if f.Path == entry.Source {
+			a.mu.RUnlock()
+			return nil, fmt.Errorf("이미 목록에 있는 파일입니다: %s", f.Name)
+		}
+	}
+	a.mu.RUnlock()
+
+	settings, err := a.encoder.PrepareSettings(entry.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if result := a.AddFiles([]string{entry.Source}); len(result.Errors) > 0 {
+		return nil, fmt.Errorf("%s", result.Errors[0])
+	}
+
+	opts := entry.Options
+	opts.Settings = &settings
+	opts.Preset = entry.Preset
+	opts.OutputDir = entry.OutputDir
+
+	a.mu.Lock()
+	a.setFileOptions(entry.Source, opts)
+	a.mu.Unlock()
+
+	return entry, nil
 }
 
 // OpenFileDialog opens a file selection dialog
@@ -9,10 +9,148 @@ export interface FileInfo {
   framerate: number;
   codec: string;
   audioCodec: string;
+  audioTracks: AudioTrack[];
+  audioTracksKnown: boolean;  // false = tracks not fully read, audio encoded as before
+  bitDepth: number;
+  colorPrimaries: string;  // e.g. "bt709", "bt2020" ("" = unknown)
+  colorTransfer: string;   // e.g. "bt709", "smpte2084" (PQ), "arib-std-b67" (HLG)
+  colorSpace: string;
+  colorRange: string;      // "tv" or "pc"
+  masterDisplay: string;
+  maxCll: number;
+  maxFall: number;
+  audioSkewMs: number;     // existing audio start relative to video (edit list / CodecDelay)
   fileSize: number;
+  trimmedSeconds: number;  // length after trimming (0 = untrimmed)
   hasDurationMismatch: boolean;
 }
 
+// Source audio track
+export interface AudioTrack {
+  index: number;     // as used by -map 0:a:N
+  codec: string;
+  channels: number;
+  language: string;  // ISO 639-2 ("" = unknown)
+}
+
+// Settings chosen for one file
+export interface JobOptions {
+  audioOffsetMs: number;  // + = audio later
+  screenLabel: string;    // burned into the calibration output
+  trimStart: number;      // seconds cut from the start by sync analysis
+  inPoint: number;        // source seconds (0 = start)
+  outPoint: number;       // source seconds (0 = end)
+  // Set for re-queued history jobs
+  settings?: Record<string, unknown>;  // encoder-wide settings of the past job
+  preset?: string;        // "" = batch preset
+  outputDir?: string;     // "" = batch folder
+}
+
+// Segment inserted before or after each file
+export interface Roll {
+  kind: 'black' | 'image' | 'clip' | '';
+  path: string;                                     // image or clip file
+  duration: { frames: number; seconds: number };   // black/image length (frames take precedence)
+  clipDuration: number;                             // filled in by the backend
+  clipHasAudio: boolean;
+}
+
+// Audio-based start offset of a file against the reference
+export interface SyncOffset {
+  path: string;
+  offsetMs: number;      // + = the same moment happens later in this file
+  offsetFrames: number;
+  confidence: number;    // 0..1
+  error?: string;
+}
+
+// Sync flash found in a file
+export interface FlashSync {
+  path: string;
+  frame: number;
+  time: number;   // seconds
+  jump: number;   // mean luma rise (0-255)
+  error?: string;
+}
+
+// Loop seam verification and seamless loop file
+export interface LoopOptions {
+  verify: boolean;
+  crossfade: number;  // seconds (0 = no loop file)
+}
+
+// Keyframe alignment of an output with the batch reference (encoding:allComplete)
+export interface KeyframeCheck {
+  keyframes: number;
+  reference: string;
+  extraCount: number;
+  missingCount: number;
+  extra: number[];    // seconds
+  missing: number[];  // seconds
+  passed: boolean;
+}
+
+// Re-hash of a copied output against its .sha256 sidecar or manifest entry
+export interface ChecksumResult {
+  path: string;
+  expected: string;
+  actual?: string;
+  status: 'ok' | 'mismatch' | 'missing' | 'error';
+  error?: string;
+}
+
+// Batch report export format (ExportReport)
+export type ReportFormat = 'json' | 'csv' | 'html';
+
+// Encode history search (SearchHistory); empty fields match everything
+export interface HistoryFilter {
+  name: string;
+  from: string;    // "YYYY-MM-DD"
+  to: string;      // "YYYY-MM-DD", inclusive
+  preset: string;
+  status: string;
+  limit: number;   // 0 = all
+}
+
+// Finished job in the encode history
+export interface HistoryEntry {
+  id: string;
+  finished: string;
+  source: string;
+  output: string;
+  outputDir: string;
+  status: string;
+  error?: string;
+  preset: string;
+  encodeSeconds: number;
+  outputSize: number;
+  sha256?: string;
+  options: JobOptions;
+}
+
+// Multi-screen sync test pattern
+export interface TestPatternOptions {
+  screens: number;
+  duration: number;       // seconds (0 = 60)
+  flashInterval: number;  // seconds (0 = 2)
+}
+
+// Fade durations in seconds (0 = no fade)
+export interface FadeOptions {
+  videoIn: number;
+  videoOut: number;
+  audioIn: number;
+  audioOut: number;
+}
+
+// Audio handling of a preset
+export interface AudioOptions {
+  mode: 'aac' | 'copy' | 'none' | '';
+  bitrate: number;   // kbps (0 = by channel layout)
+  channels: 'stereo' | '5.1' | '7.1' | 'discrete' | '';
+  track: number;     // source audio track index
+}
+
 // Preset definition
 export interface Preset {
   name: string;
@@ -24,6 +162,14 @@ export interface Preset {
   fps: number;
   useSourceFps: boolean;
   useSourceRes: boolean;
+  bitDepth: number;            // 8 or 10 (0 = 8-bit)
+  useSourceBitDepth: boolean;  // 10-bit output for 10-bit sources
+  maxBitrate: number; // VBV max bitrate in kbps (0 = derive from level)
+  bufSize: number;    // VBV buffer size in kbit (0 = level CPB size)
+  toneMap: string;      // HDR to SDR algorithm: "hable" | "mobius" | "reinhard"
+  preserveHdr: boolean; // keep HDR10/HLG instead of tone mapping
+  forceBt709: boolean;  // convert and tag SDR output as BT.709 limited range
+  audio: AudioOptions;
 }
 
 // Encoding progress
@@ -71,6 +217,18 @@ export interface HWEncoder {
   description: string;
   available: boolean;
   priority: number;
+  device: string; // DRM render node for VAAPI/QSV ('' = default)
+  capabilities?: EncoderCapabilities;
+}
+
+// Probed encoder limits
+export interface EncoderCapabilities {
+  maxWidth: number;
+  maxHeight: number;
+  main10: boolean;
+  profiles: string[];
+  levels: string[];
+  bFrames: boolean;
 }
 
 // Quality level option
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// appDirName is the per-user config directory name
+const appDirName = "SyncLauperVideoConverter"
+
+// Dir returns the per-user config directory, creating it if needed
+func Dir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(base, appDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Path returns the path of a file inside the config directory
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
@@ -0,0 +1,273 @@
+package encoder
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"syncLauperVideoConverter/internal/cmdutil"
+	"syncLauperVideoConverter/internal/fileinfo"
+	"syncLauperVideoConverter/internal/preset"
+)
+
+// EncoderCapabilities describes what an encoder can handle, probed with small test encodes
+type EncoderCapabilities struct {
+	MaxWidth  int      `json:"maxWidth"`  // largest probed width that encoded (0 = unknown)
+	MaxHeight int      `json:"maxHeight"` // largest probed height that encoded (0 = unknown)
+	Main10    bool     `json:"main10"`    // 10-bit (main10) encoding works
+	Profiles  []string `json:"profiles"`  // e.g., ["main", "main10"]
+	Levels    []string `json:"levels"`    // e.g., ["4.1", "5.0", "5.1"] (empty = unknown)
+	BFrames   bool     `json:"bFrames"`   // B-frames are accepted
+}
+
+// SupportsProfile reports whether the encoder was verified with the given H.265 profile
+func (c *EncoderCapabilities) SupportsProfile(profile string) bool {
+	for _, p := range c.Profiles {
+		if p == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsLevel reports whether the encoder was verified at the given H.265 level
+func (c *EncoderCapabilities) SupportsLevel(level string) bool {
+	for _, l := range c.Levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// levelProbes are the resolution/framerate combinations verified per level,
+// matching the presets that use each level. DCI 4K and 8K cover sources kept
+// at their own resolution.
+var levelProbes = []struct {
+	Level  string
+	Width  int
+	Height int
+	FPS    int
+}{
+	{"4.1", 1920, 1080, 60},
+	{"5.0", 3840, 2160, 30},
+	{"5.1", 3840, 2160, 60},
+	{"5.0", 4096, 2160, 30},
+	{"6.0", 8192, 4320, 30},
+}
+
+// levelProbed reports whether the given level is verified by a probe
+func levelProbed(level string) bool {
+	for _, lp := range levelProbes {
+		if lp.Level == level {
+			return true
+		}
+	}
+	return false
+}
+
+// largestProbe returns the largest probed resolution. Larger outputs were never
+// tried, so their support is unknown.
+func largestProbe() (width, height int) {
+	for _, lp := range levelProbes {
+		if lp.Width*lp.Height > width*height {
+			width, height = lp.Width, lp.Height
+		}
+	}
+	return width, height
+}
+
+// encodeProbe describes a small test encode
+type encodeProbe struct {
+	Width   int
+	Height  int
+	FPS     int    // 0 = lavfi default
+	Frames  int    // 0 = 1 frame
+	PixFmt  string // "" = encoder default
+	Profile string // "" = encoder default
+	Level   string // "" = encoder default
+	BFrames int    // 0 = encoder default
+}
+
+// ProbeCapabilities runs test encodes to find the limits of an encoder on the given render device
+func (f *FFmpeg) ProbeCapabilities(encoderID string, device string) *EncoderCapabilities {
+	caps := &EncoderCapabilities{Profiles: []string{"main"}}
+
+	if encoderID == "libx265" {
+		// Software encoding is only limited by the level definitions
+		caps.MaxWidth, caps.MaxHeight = 8192, 4320
+		for _, lp := range levelProbes {
+			if !caps.SupportsLevel(lp.Level) {
+				caps.Levels = append(caps.Levels, lp.Level)
+			}
+		}
+		caps.BFrames = true
+	} else {
+		for _, lp := range levelProbes {
+			err := f.probeEncode(encoderID, device, encodeProbe{
+				Width:  lp.Width,
+				Height: lp.Height,
+				FPS:    lp.FPS,
+				Frames: 2,
+				Level:  lp.Level,
+			})
+			if err != nil {
+				fmt.Printf("[HWAccel] %s: level %s (%dx%d@%d) not supported\n", encoderID, lp.Level, lp.Width, lp.Height, lp.FPS)
+				continue
+			}
+			if !caps.SupportsLevel(lp.Level) {
+				caps.Levels = append(caps.Levels, lp.Level)
+			}
+			if lp.Width*lp.Height > caps.MaxWidth*caps.MaxHeight {
+				caps.MaxWidth, caps.MaxHeight = lp.Width, lp.Height
+			}
+		}
+
+		caps.BFrames = f.probeEncode(encoderID, device, encodeProbe{
+			Width:   256,
+			Height:  256,
+			Frames:  4,
+			BFrames: 2,
+		}) == nil
+	}
+
+	caps.Main10 = f.probeEncode(encoderID, device, encodeProbe{
+		Width:   256,
+		Height:  256,
+		PixFmt:  preset.PixelFormat(encoderID, 10),
+		Profile: "main10",
+	}) == nil
+	if caps.Main10 {
+		caps.Profiles = append(caps.Profiles, "main10")
+	}
+
+	return caps
+}
+
+// probeEncode runs a short encode of generated frames and reports whether it succeeded
+func (f *FFmpeg) probeEncode(encoderID string, device string, probe encodeProbe) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Build test encode command: generate null video frames and encode them
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "error",
+	}
+
+	// Add pre-input args for hardware encoders
+	args = append(args, preset.GetPreInputArgs(encoderID, device)...)
+
+	source := fmt.Sprintf("nullsrc=s=%dx%d", probe.Width, probe.Height)
+	if probe.FPS > 0 {
+		source += fmt.Sprintf(":r=%d", probe.FPS)
+	}
+	frames := probe.Frames
+	if frames <= 0 {
+		frames = 1
+	}
+
+	args = append(args, "-f", "lavfi", "-i", source)
+	if encoderID == "hevc_vaapi" {
+		// VAAPI only encodes hardware surfaces, so upload the generated frames
+		pixFmt := "nv12"
+		if probe.PixFmt != "" {
+			pixFmt = strings.TrimSuffix(probe.PixFmt, "le")
+		}
+		args = append(args, "-vf", "format="+pixFmt+",hwupload")
+	} else if probe.PixFmt != "" {
+		args = append(args, "-pix_fmt", probe.PixFmt)
+	}
+	args = append(args, "-c:v", encoderID)
+	if probe.Profile != "" {
+		args = append(args, "-profile:v", probe.Profile)
+	}
+	// VideoToolbox and QSV are run without an explicit level in production
+	if probe.Level != "" && encoderID != "hevc_videotoolbox" && encoderID != "hevc_qsv" {
+		args = append(args, "-level:v", probe.Level)
+	}
+	if probe.BFrames > 0 {
+		if encoderID == "libx265" {
+			args = append(args, "-x265-params", fmt.Sprintf("bframes=%d", probe.BFrames))
+		} else {
+			args = append(args, "-bf", fmt.Sprintf("%d", probe.BFrames))
+		}
+	}
+	args = append(args,
+		"-frames:v", fmt.Sprintf("%d", frames),
+		"-f", "null", "-",
+	)
+
+	cmd := exec.CommandContext(ctx, f.config.ExecutablePath, args...)
+	cmdutil.HideWindow(cmd)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s 인코더 테스트 실패: %s", encoderID, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// availableEncoder returns the detected encoder with the given ID (nil = not available)
+func (e *Encoder) availableEncoder(encoderID string) *HWEncoder {
+	for _, enc := range e.GetAvailableEncoders() {
+		if enc.ID == encoderID {
+			return &enc
+		}
+	}
+	return nil
+}
+
+// capabilities returns the probed capabilities of an encoder (nil = unknown)
+func (e *Encoder) capabilities(encoderID string) *EncoderCapabilities {
+	if enc := e.availableEncoder(encoderID); enc != nil {
+		return enc.Capabilities
+	}
+	return nil
+}
+
+// CheckEncoderSupport verifies that the encoder of the settings can handle the preset
+// for a source file, so unsupported combinations are refused before the batch starts.
+// 10-bit output the preset picks for a source is auto-adjusted to 8-bit during the
+// batch; an explicit 10-bit or HDR preserve override is refused here instead.
+func (e *Encoder) CheckEncoderSupport(p *preset.Preset, s Settings, src *fileinfo.FileInfo) error {
+	return checkCapabilities(e.capabilities(s.Encoder), p, s, src)
+}
+
+// checkCapabilities checks the probed capabilities of an encoder against a source
+// encoded with the preset and settings (nil caps = not probed)
+func checkCapabilities(caps *EncoderCapabilities, p *preset.Preset, s Settings, src *fileinfo.FileInfo) error {
+	if caps == nil {
+		return nil // not probed, nothing to check against
+	}
+
+	if !caps.SupportsProfile("main10") && (s.BitDepth == 10 || s.HDRMode == preset.HDRModePreserve) {
+		return fmt.Errorf("선택한 인코더는 10비트(main10) 인코딩을 지원하지 않습니다")
+	}
+
+	// Every level probe failing (e.g. the driver rejects -level:v) says nothing about
+	// the limits, so the encode is allowed as before the probe existed
+	if len(caps.Levels) == 0 {
+		return nil
+	}
+
+	width, height, fps, level := p.EffectiveValues(presetSourceInfo(src))
+
+	// Compare long and short edges so portrait sources are handled. Only sizes up to
+	// the largest probe are refused; beyond it the limit is unknown.
+	longEdge, shortEdge := max(width, height), min(width, height)
+	probeWidth, probeHeight := largestProbe()
+	if longEdge <= probeWidth && shortEdge <= probeHeight &&
+		(longEdge > max(caps.MaxWidth, caps.MaxHeight) || shortEdge > min(caps.MaxWidth, caps.MaxHeight)) {
+		return fmt.Errorf("%s: 선택한 인코더는 %dx%d 해상도를 지원하지 않습니다 (최대 %dx%d)",
+			src.Name, width, height, caps.MaxWidth, caps.MaxHeight)
+	}
+
+	if level != "auto" && levelProbed(level) && !caps.SupportsLevel(level) {
+		return fmt.Errorf("%s: 선택한 인코더는 레벨 %s (%dx%d@%.3gfps)를 지원하지 않습니다",
+			src.Name, level, width, height, fps)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package encoder
+
+import (
+	"testing"
+
+	"syncLauperVideoConverter/internal/fileinfo"
+	"syncLauperVideoConverter/internal/preset"
+)
+
+func TestCheckCapabilities(t *testing.T) {
+	uhd := &EncoderCapabilities{
+		MaxWidth:  3840,
+		MaxHeight: 2160,
+		Profiles:  []string{"main"},
+		Levels:    []string{"4.1", "5.0", "5.1"},
+	}
+	allProbes := &EncoderCapabilities{
+		MaxWidth:  8192,
+		MaxHeight: 4320,
+		Main10:    true,
+		Profiles:  []string{"main", "main10"},
+		Levels:    []string{"4.1", "5.0", "5.1", "6.0"},
+	}
+	source := preset.Preset{Name: "source", UseSourceRes: true, UseSourceFPS: true, Level: "auto"}
+	src := func(width, height int, fps float64) *fileinfo.FileInfo {
+		return &fileinfo.FileInfo{Name: "in.mov", Width: width, Height: height, Framerate: fps, DurationSeconds: 10}
+	}
+
+	tests := []struct {
+		name     string
+		caps     *EncoderCapabilities
+		settings Settings
+		src      *fileinfo.FileInfo
+		wantErr  bool
+	}{
+		{"not probed", nil, Settings{BitDepth: 10}, src(8192, 4320, 60), false},
+		{"uhd on uhd encoder", uhd, Settings{}, src(3840, 2160, 30), false},
+		{"portrait uhd", uhd, Settings{}, src(2160, 3840, 30), false},
+		{"dci 4k probe failed", uhd, Settings{}, src(4096, 2160, 24), true},
+		{"dci 4k probe passed", allProbes, Settings{}, src(4096, 2160, 24), false},
+		{"above largest probe is unknown", allProbes, Settings{}, src(10240, 4320, 30), false},
+		{"forced 10-bit without main10", uhd, Settings{BitDepth: 10}, src(1920, 1080, 30), true},
+		{"hdr preserve without main10", uhd, Settings{HDRMode: preset.HDRModePreserve}, src(1920, 1080, 30), true},
+		{"forced 10-bit with main10", allProbes, Settings{BitDepth: 10}, src(1920, 1080, 30), false},
+		{"all level probes failed", &EncoderCapabilities{Profiles: []string{"main"}}, Settings{}, src(4096, 2160, 24), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCapabilities(tt.caps, &source, tt.settings, tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLargestProbe(t *testing.T) {
+	if width, height := largestProbe(); width != 8192 || height != 4320 {
+		t.Errorf("largest probe = %dx%d, want 8192x4320", width, height)
+	}
+	if !levelProbed("5.1") || levelProbed("6.2") {
+		t.Error("levelProbed doesn't match the probe table")
+	}
+}
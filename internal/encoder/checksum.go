@@ -0,0 +1,217 @@
+package encoder
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"syncLauperVideoConverter/internal/fileinfo"
+)
+
+// checksumExt is the extension of sha256sum-compatible sidecar files
+const checksumExt = ".sha256"
+
+// Checksum verification statuses
+const (
+	ChecksumOK       = "ok"
+	ChecksumMismatch = "mismatch"
+	ChecksumMissing  = "missing"
+	ChecksumError    = "error"
+)
+
+// ChecksumResult represents the verification of one file against its recorded checksum
+type ChecksumResult struct {
+	Path     string `json:"path"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// fileSHA256 returns the hex SHA-256 digest of a file
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// probeOutput lists the video packets of a finished output and returns its hex
+// SHA-256 digest, reading the file once
+func probeOutput(path string) ([]fileinfo.Packet, string, error) {
+	hash := sha256.New()
+	packets, err := fileinfo.ProbePacketsHashed(path, hash)
+	if err != nil {
+		return nil, "", err
+	}
+	return packets, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeChecksum hashes a finished file and writes the digest to a sidecar file
+func writeChecksum(path string) (string, error) {
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return "", err
+	}
+	return sum, writeChecksumFile(path, sum)
+}
+
+// writeChecksumFile writes a digest to the sidecar file of path in sha256sum format
+func writeChecksumFile(path string, sum string) error {
+	line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(path))
+	return os.WriteFile(path+checksumExt, []byte(line), 0644)
+}
+
+// checksumSet collects the expected checksums found in a verified folder
+type checksumSet struct {
+	root     string
+	expected map[string]string // file path -> checksum
+	outside  map[string]string // entries resolving outside root, as written -> checksum
+}
+
+func newChecksumSet(root string) *checksumSet {
+	return &checksumSet{
+		root:     filepath.Clean(root),
+		expected: make(map[string]string),
+		outside:  make(map[string]string),
+	}
+}
+
+// add records the checksum of name, relative to the folder listFile is in. Absolute
+// names and names resolving outside the verified folder are set aside unread.
+func (s *checksumSet) add(listFile string, name string, sum string) {
+	path := filepath.FromSlash(name)
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		s.outside[name] = sum
+		return
+	}
+	path = filepath.Join(filepath.Dir(listFile), path)
+	if rel, err := filepath.Rel(s.root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		s.outside[name] = sum
+		return
+	}
+	s.expected[path] = sum
+}
+
+// VerifyFolder re-hashes the files of a folder (e.g. a copy on a player drive)
+// against the checksums in its .sha256 sidecars and batch manifests. Entries
+// pointing outside the folder are reported as errors without being read.
+func VerifyFolder(dir string) ([]ChecksumResult, error) {
+	sums := newChecksumSet(dir)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch {
+		case strings.EqualFold(filepath.Ext(path), checksumExt):
+			return readChecksumFile(path, sums)
+		case d.Name() == manifestName:
+			return readManifestChecksums(path, sums)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(sums.expected) == 0 && len(sums.outside) == 0 {
+		return nil, fmt.Errorf("체크섬 파일을 찾을 수 없습니다: %s", dir)
+	}
+
+	paths := make([]string, 0, len(sums.expected))
+	for path := range sums.expected {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	outside := make([]string, 0, len(sums.outside))
+	for name := range sums.outside {
+		outside = append(outside, name)
+	}
+	sort.Strings(outside)
+
+	results := make([]ChecksumResult, 0, len(paths)+len(outside))
+	for _, name := range outside {
+		results = append(results, ChecksumResult{
+			Path:     name,
+			Expected: sums.outside[name],
+			Status:   ChecksumError,
+			Error:    "검증 폴더 밖의 경로는 확인하지 않습니다",
+		})
+	}
+	for _, path := range paths {
+		result := ChecksumResult{Path: path, Expected: sums.expected[path]}
+		sum, err := fileSHA256(path)
+		switch {
+		case os.IsNotExist(err):
+			result.Status = ChecksumMissing
+		case err != nil:
+			result.Status = ChecksumError
+			result.Error = err.Error()
+		case sum != result.Expected:
+			result.Actual = sum
+			result.Status = ChecksumMismatch
+		default:
+			result.Actual = sum
+			result.Status = ChecksumOK
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// readChecksumFile reads the entries of a sha256sum-format file. Paths are
+// relative to the file.
+func readChecksumFile(path string, sums *checksumSet) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		sum, name, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok || len(sum) != sha256.Size*2 {
+			continue
+		}
+		// "*" marks binary mode in sha256sum output
+		name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+		sums.add(path, name, strings.ToLower(sum))
+	}
+	return scanner.Err()
+}
+
+// readManifestChecksums reads the output checksums of a batch manifest. Paths are
+// relative to the manifest.
+func readManifestChecksums(path string, sums *checksumSet) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("%s: %v", filepath.Base(path), err)
+	}
+	for _, entry := range manifest.Outputs {
+		if entry.SHA256 == "" {
+			continue
+		}
+		sums.add(path, entry.Path, entry.SHA256)
+	}
+	return nil
+}
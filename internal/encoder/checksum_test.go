@@ -0,0 +1,126 @@
+package encoder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadChecksumFile(t *testing.T) {
+	dir := t.TempDir()
+	sum := strings.Repeat("ab", 32)
+	content := strings.Join([]string{
+		sum + "  text.mkv",
+		strings.ToUpper(sum) + " *binary.mkv",
+		sum + "  sub/nested.mkv",
+		"",
+		"not a checksum line",
+		"abcd  short.mkv",
+		sum + "  ../escape.mkv",
+		sum + "  /abs/path.mkv",
+	}, "\n")
+	path := filepath.Join(dir, "outputs.sha256")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sums := newChecksumSet(dir)
+	if err := readChecksumFile(path, sums); err != nil {
+		t.Fatal(err)
+	}
+	expected := sums.expected
+
+	want := map[string]string{
+		filepath.Join(dir, "text.mkv"):          sum,
+		filepath.Join(dir, "binary.mkv"):        sum,
+		filepath.Join(dir, "sub", "nested.mkv"): sum,
+	}
+	if len(expected) != len(want) {
+		t.Fatalf("got %d entries %v, want %d", len(expected), expected, len(want))
+	}
+	for p, s := range want {
+		if expected[p] != s {
+			t.Errorf("%s = %q, want %q", p, expected[p], s)
+		}
+	}
+	for _, name := range []string{"../escape.mkv", "/abs/path.mkv"} {
+		if _, ok := sums.outside[name]; !ok {
+			t.Errorf("%s not set aside as outside the folder: %v", name, sums.outside)
+		}
+	}
+}
+
+func TestVerifyFolder(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("good.mkv", "good")
+	write("bad.mkv", "good")
+	write("sub/copied.mkv", "copied")
+	for _, name := range []string{"good.mkv", "bad.mkv", "sub/copied.mkv"} {
+		if _, err := writeChecksum(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("bad.mkv", "corrupted")
+	write("gone.mkv", "gone")
+	if _, err := writeChecksum(filepath.Join(dir, "gone.mkv")); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(dir, "gone.mkv"))
+	write("outside.mkv", "outside")
+	outsideSum, err := fileSHA256(filepath.Join(dir, "outside.mkv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	write("sub/"+manifestName, `{"outputs":[{"path":"../outside.mkv","sha256":"`+outsideSum+`"}]}`)
+
+	results, err := VerifyFolder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"good.mkv":       ChecksumOK,
+		"bad.mkv":        ChecksumMismatch,
+		"sub/copied.mkv": ChecksumOK,
+		"gone.mkv":       ChecksumMissing,
+		"outside.mkv":    ChecksumOK, // inside the verified folder
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for _, r := range results {
+		rel, _ := filepath.Rel(dir, r.Path)
+		if status := want[filepath.ToSlash(rel)]; r.Status != status {
+			t.Errorf("%s: status %q, want %q", rel, r.Status, status)
+		}
+	}
+
+	// Verifying only sub must not read ../outside.mkv listed in its manifest
+	results, err = VerifyFolder(filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2: %+v", len(results), results)
+	}
+	for _, r := range results {
+		if r.Path == "../outside.mkv" && (r.Status != ChecksumError || r.Actual != "") {
+			t.Errorf("outside entry = %+v, want an error without reading the file", r)
+		}
+	}
+
+	if _, err := VerifyFolder(t.TempDir()); err == nil {
+		t.Error("VerifyFolder on a folder without checksums returned no error")
+	}
+}
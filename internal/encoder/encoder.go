@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"syncLauperVideoConverter/internal/fileinfo"
 	"syncLauperVideoConverter/internal/preset"
@@ -22,6 +23,44 @@ type EncodingJob struct {
 	Status     string             `json:"status"`
 	Progress   float64            `json:"progress"`
 	Error      string             `json:"error,omitempty"`
+
+	BitrateCheck *BitrateCheck   `json:"bitrateCheck,omitempty"` // peak bitrate verification (nil = not checked)
+	Loudness     *LoudnessReport `json:"loudness,omitempty"`     // before/after loudness (nil = not normalized)
+	FrameCheck   *FrameCheck     `json:"frameCheck,omitempty"`   // intro/outro frame count verification (nil = not checked)
+
+	CalibrationPath string     `json:"calibrationPath,omitempty"` // output with burned-in overlay ("" = not made)
+	LoopCheck       *LoopCheck `json:"loopCheck,omitempty"`       // loop seam verification of the output (nil = not checked)
+	LoopPath        string     `json:"loopPath,omitempty"`        // seamless loop variant ("" = not made)
+	LoopPathCheck   *LoopCheck `json:"loopPathCheck,omitempty"`   // loop seam verification of the loop variant
+
+	KeyframeCheck *KeyframeCheck `json:"keyframeCheck,omitempty"` // keyframe alignment with its rendition group (nil = not checked)
+
+	EncoderSettings *Settings `json:"encoderSettings,omitempty"` // encoder-wide settings the job ran with (nil = not started)
+
+	Settings *OutputSettings `json:"settings,omitempty"` // settings of the completed output
+	SHA256   string          `json:"sha256,omitempty"`   // checksum of the output, also in <output>.sha256
+
+	packets []fileinfo.Packet // video packets of the output, probed once after the encode
+
+	OutputSize    int64   `json:"outputSize,omitempty"`    // bytes
+	EncodeSeconds float64 `json:"encodeSeconds,omitempty"` // wall time of the main encode pass
+
+	Options JobOptions `json:"options"`
+}
+
+// JobOptions are settings chosen for one file rather than the whole batch.
+// The sync trim moves the in and out points together, so trimmed files stay aligned.
+type JobOptions struct {
+	AudioOffsetMs int     `json:"audioOffsetMs"` // audio shift in milliseconds (+ = audio later)
+	ScreenLabel   string  `json:"screenLabel"`   // label burned into the calibration output ("" = none)
+	TrimStart     float64 `json:"trimStart"`     // seconds cut from the start by sync analysis
+	InPoint       float64 `json:"inPoint"`       // source seconds where the output starts (0 = start)
+	OutPoint      float64 `json:"outPoint"`      // source seconds where the output ends (0 = end)
+
+	// Set when a past job is re-queued, so it runs as before without changing the batch
+	Settings  *Settings `json:"settings,omitempty"`  // encoder-wide settings (nil = current settings)
+	Preset    string    `json:"preset,omitempty"`    // preset name ("" = batch preset)
+	OutputDir string    `json:"outputDir,omitempty"` // output folder ("" = batch folder)
 }
 
 // Encoder manages encoding jobs
@@ -37,16 +76,29 @@ type Encoder struct {
 	completeCb      func(result *EncodeResult, job *EncodingJob)
 	errorCb         func(err error, job *EncodingJob)
 	allCompleteCb   func(completed int, failed int)
-	selectedEncoder    string // Selected encoder ID (e.g., "libx265", "hevc_nvenc")
-	qualityLevel       int    // CRF value (0 = use default)
-	blackIntroDuration int    // Black intro duration in seconds (0 = disabled)
+	selectedEncoder string               // Selected encoder ID (e.g., "libx265", "hevc_vaapi@/dev/dri/renderD129")
+	qualityLevel    int                  // CRF value (0 = use default)
+	hwDecode        bool                 // Decode/scale on the GPU when the source codec allows
+	bitDepth        int                  // Output bit depth override, 8 or 10 (0 = use preset)
+	hdrMode         string               // HDR handling override, "tonemap" or "preserve" ("" = use preset)
+	toneMap         string               // Tonemap algorithm override ("" = use preset)
+	audioOptions    *preset.AudioOptions // Audio options override (nil = use preset)
+	loudnessTarget  float64              // EBU R128 integrated loudness target in LUFS (0 = disabled)
+	preRoll         preset.Roll          // Segment before each source (zero value = disabled)
+	postRoll        preset.Roll          // Segment after each source (zero value = disabled)
+	calibration     bool                 // Also write a variant with burned-in timecode, filename and screen label
+	loopOptions     LoopOptions          // Loop seam verification and seamless loop variant
+	fades           preset.FadeOptions   // Fade in/out durations of the source (zero value = no fades)
+	manifestPaths   []string             // Manifests of the last finished batch, one per output folder
+	report          *BatchReport         // Report of the last finished batch (nil = none)
 }
 
 // NewEncoder creates a new Encoder instance
 func NewEncoder() *Encoder {
 	return &Encoder{
-		ffmpeg: NewFFmpeg(DefaultFFmpegConfig()),
-		jobs:   make([]*EncodingJob, 0),
+		ffmpeg:   NewFFmpeg(DefaultFFmpegConfig()),
+		jobs:     make([]*EncodingJob, 0),
+		hwDecode: true,
 	}
 }
 
@@ -93,6 +145,11 @@ func (e *Encoder) GetAvailableEncoders() []HWEncoder {
 	return e.ffmpeg.GetAvailableHWEncoders()
 }
 
+// RedetectEncoders runs hardware encoder detection again, bypassing the cache
+func (e *Encoder) RedetectEncoders() []HWEncoder {
+	return e.ffmpeg.RedetectHWEncoders()
+}
+
 // SetEncoder sets the encoder to use
 func (e *Encoder) SetEncoder(encoderID string) {
 	e.mu.Lock()
@@ -124,22 +181,182 @@ func (e *Encoder) GetQuality() int {
 	return e.qualityLevel
 }
 
-// SetBlackIntroDuration sets the black intro duration in seconds
-func (e *Encoder) SetBlackIntroDuration(seconds int) {
+// SetBitDepth sets the output bit depth override (8, 10, or 0 to use the preset)
+func (e *Encoder) SetBitDepth(bits int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.bitDepth = bits
+}
+
+// GetBitDepth returns the output bit depth override
+func (e *Encoder) GetBitDepth() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.bitDepth
+}
+
+// SetHDRMode sets how HDR sources are handled ("tonemap", "preserve", or "" to use the preset)
+func (e *Encoder) SetHDRMode(mode string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.hdrMode = mode
+}
+
+// GetHDRMode returns the HDR handling override
+func (e *Encoder) GetHDRMode() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.hdrMode
+}
+
+// SetToneMap sets the HDR to SDR tonemap algorithm ("hable", "mobius", "reinhard", or "" to use the preset)
+func (e *Encoder) SetToneMap(algorithm string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.toneMap = algorithm
+}
+
+// GetToneMap returns the tonemap algorithm override
+func (e *Encoder) GetToneMap() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.toneMap
+}
+
+// SetAudioOptions overrides the preset audio options (nil = use the preset)
+func (e *Encoder) SetAudioOptions(opts *preset.AudioOptions) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.audioOptions = opts
+}
+
+// GetAudioOptions returns the audio options override
+func (e *Encoder) GetAudioOptions() *preset.AudioOptions {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.audioOptions
+}
+
+// SetLoudnessTarget enables two-pass loudness normalization to the given LUFS (0 = disabled)
+func (e *Encoder) SetLoudnessTarget(lufs float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.loudnessTarget = lufs
+}
+
+// GetLoudnessTarget returns the loudness normalization target in LUFS
+func (e *Encoder) GetLoudnessTarget() float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.loudnessTarget
+}
+
+// SetHWDecode enables or disables hardware decoding and scaling
+func (e *Encoder) SetHWDecode(enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.hwDecode = enabled
+}
+
+// GetHWDecode returns whether hardware decoding and scaling is enabled
+func (e *Encoder) GetHWDecode() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.hwDecode
+}
+
+// SetPreRoll sets the black, image or clip segment inserted before each source
+func (e *Encoder) SetPreRoll(r preset.Roll) error {
+	r, err := prepareRoll(r)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.preRoll = r
+	return nil
+}
+
+// GetPreRoll returns the current pre-roll
+func (e *Encoder) GetPreRoll() preset.Roll {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.preRoll
+}
+
+// SetPostRoll sets the black, image or clip segment inserted after each source
+func (e *Encoder) SetPostRoll(r preset.Roll) error {
+	r, err := prepareRoll(r)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.postRoll = r
+	return nil
+}
+
+// GetPostRoll returns the current post-roll
+func (e *Encoder) GetPostRoll() preset.Roll {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.postRoll
+}
+
+// AnalyzeSyncOffsets cross-correlates the audio of each file against the reference file
+func (e *Encoder) AnalyzeSyncOffsets(reference string, files []*fileinfo.FileInfo) ([]SyncOffset, error) {
+	return e.ffmpeg.AnalyzeSyncOffsets(context.Background(), reference, files)
+}
+
+// DetectSyncFlashes finds the sync flash in the first seconds of each file
+func (e *Encoder) DetectSyncFlashes(files []*fileinfo.FileInfo, seconds float64) ([]FlashSync, error) {
+	return e.ffmpeg.DetectSyncFlashes(context.Background(), files, seconds)
+}
+
+// SetLoopOptions sets loop seam verification and the seamless loop crossfade
+func (e *Encoder) SetLoopOptions(opts LoopOptions) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.loopOptions = opts
+}
+
+// GetLoopOptions returns the current loop options
+func (e *Encoder) GetLoopOptions() LoopOptions {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.loopOptions
+}
+
+// SetCalibrationOverlay enables or disables the calibration output variant
+func (e *Encoder) SetCalibrationOverlay(enabled bool) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	e.blackIntroDuration = seconds
+	e.calibration = enabled
 }
 
-// GetBlackIntroDuration returns the current black intro duration
-func (e *Encoder) GetBlackIntroDuration() int {
+// GetCalibrationOverlay returns whether the calibration output variant is written
+func (e *Encoder) GetCalibrationOverlay() bool {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
-	return e.blackIntroDuration
+	return e.calibration
+}
+
+// SetFades sets the video and audio fade durations
+func (e *Encoder) SetFades(fades preset.FadeOptions) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fades = fades
+}
+
+// GetFades returns the current fade durations
+func (e *Encoder) GetFades() preset.FadeOptions {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.fades
 }
 
 // AddJob adds a new encoding job to the queue
-func (e *Encoder) AddJob(inputPath string, outputDir string, p *preset.Preset) (*EncodingJob, error) {
+func (e *Encoder) AddJob(inputPath string, outputDir string, p *preset.Preset, opts JobOptions) (*EncodingJob, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -167,6 +384,7 @@ func (e *Encoder) AddJob(inputPath string, outputDir string, p *preset.Preset) (
 		FileInfo:   info,
 		Status:     StatusWaiting,
 		Progress:   0,
+		Options:    opts,
 	}
 
 	e.jobs = append(e.jobs, job)
@@ -186,6 +404,81 @@ func (e *Encoder) ClearJobs() {
 	e.currentJob = 0
 }
 
+// verifyKeyframeAlignment compares the keyframe timestamps of every completed output
+// with the first output of its rendition group. Hardware encoders only get -g and may
+// insert extra IDR frames, which this reports per file.
+func (e *Encoder) verifyKeyframeAlignment() {
+	for _, group := range keyframeGroups(e.GetJobs()) {
+		if len(group) < 2 {
+			continue
+		}
+
+		reference := group[0]
+		referenceTimes, err := outputKeyframeTimes(reference)
+		if err != nil {
+			fmt.Printf("[Verify] %s: keyframe scan failed: %v\n", filepath.Base(reference.OutputPath), err)
+			continue
+		}
+
+		for _, job := range group {
+			times, err := outputKeyframeTimes(job)
+			if err != nil {
+				fmt.Printf("[Verify] %s: keyframe scan failed: %v\n", filepath.Base(job.OutputPath), err)
+				continue
+			}
+			check := CompareKeyframes(reference.OutputPath, referenceTimes, times)
+			if !check.Passed {
+				fmt.Printf("[Verify] %s: keyframes differ from %s (%d extra, %d missing)\n",
+					filepath.Base(job.OutputPath), filepath.Base(reference.OutputPath), check.ExtraCount, check.MissingCount)
+			}
+			e.mu.Lock()
+			job.KeyframeCheck = check
+			e.mu.Unlock()
+		}
+	}
+}
+
+// keyframeGroups splits the completed jobs into rendition groups in batch order.
+// Only outputs of the same preset, encoder and rolls are expected to share keyframes;
+// re-queued jobs with other settings form their own group.
+func keyframeGroups(jobs []*EncodingJob) [][]*EncodingJob {
+	var groups [][]*EncodingJob
+	index := make(map[OutputSettings]int)
+	for _, job := range jobs {
+		if job.Status != StatusCompleted || job.Settings == nil {
+			continue
+		}
+		key := OutputSettings{
+			Preset:         job.Settings.Preset,
+			Encoder:        job.Settings.Encoder,
+			PreRollFrames:  job.Settings.PreRollFrames,
+			PostRollFrames: job.Settings.PostRollFrames,
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], job)
+	}
+	return groups
+}
+
+// GetManifestPaths returns the manifests written for the last finished batch
+func (e *Encoder) GetManifestPaths() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]string(nil), e.manifestPaths...)
+}
+
+// GetReport returns the report of the last finished batch
+func (e *Encoder) GetReport() *BatchReport {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.report
+}
+
 // GetJobs returns all jobs
 func (e *Encoder) GetJobs() []*EncodingJob {
 	e.mu.RLock()
@@ -240,6 +533,7 @@ func (e *Encoder) Cancel() {
 
 // processQueue processes all jobs in the queue
 func (e *Encoder) processQueue() {
+	started := time.Now()
 	for {
 		e.mu.RLock()
 		if e.currentJob >= len(e.jobs) {
@@ -257,18 +551,61 @@ func (e *Encoder) processQueue() {
 		job.Status = StatusEncoding
 		e.mu.Unlock()
 
+		// Re-queued jobs run with their own settings, recorded for the history
+		settings := e.JobSettings(job.Options)
+		e.mu.Lock()
+		job.EncoderSettings = &settings
+		e.mu.Unlock()
+
 		// Build FFmpeg arguments with selected encoder
-		sourceInfo := &preset.FileInfo{
-			Width:     job.FileInfo.Width,
-			Height:    job.FileInfo.Height,
-			Framerate: job.FileInfo.Framerate,
+		sourceInfo := presetSourceInfo(job.FileInfo)
+		encoderID, renderDevice := splitEncoderID(settings.Encoder)
+		preRoll := settings.PreRoll
+		postRoll := settings.PostRoll
+
+		// 10-bit output is auto-adjusted to 8-bit when the encoder can't do main10.
+		// Keeping HDR needs main10, so such sources are tone mapped instead.
+		bitDepth := job.Preset.EffectiveBitDepth(sourceInfo, settings.BitDepth)
+		hdrMode := job.Preset.EffectiveHDRMode(sourceInfo, settings.HDRMode)
+		if hdrMode == preset.HDRModePreserve {
+			bitDepth = 10
+		}
+		if caps := e.capabilities(settings.Encoder); bitDepth == 10 && caps != nil && !caps.SupportsProfile("main10") {
+			fmt.Printf("[Encoder] %s: %s does not support main10, encoding 8-bit\n", job.FileInfo.Name, encoderID)
+			bitDepth = 8
+			if hdrMode == preset.HDRModePreserve {
+				hdrMode = preset.HDRModeToneMap
+			}
+		}
+
+		opts := preset.EncodeOptions{
+			EncoderID:     encoderID,
+			RenderDevice:  renderDevice,
+			Quality:       settings.Quality,
+			PreRoll:       preRoll,
+			PostRoll:      postRoll,
+			Fades:         settings.Fades,
+			HWDecode:      settings.HWDecode,
+			BitDepth:      bitDepth,
+			HDRMode:       hdrMode,
+			ToneMap:       settings.ToneMap,
+			Audio:         settings.Audio,
+			AudioOffsetMs: job.Options.AudioOffsetMs,
+			TrimStart:     job.Options.trimStart(),
+			TrimEnd:       job.Options.trimEnd(),
 		}
-		encoderID := e.GetSelectedEncoder()
-		quality := e.GetQuality()
-		blackIntro := e.GetBlackIntroDuration()
-		args := job.Preset.ToFFmpegArgsWithEncoder(job.InputPath, job.OutputPath, sourceInfo, encoderID, quality, blackIntro)
 
 		// Progress callback wrapper
+		calibration := settings.Calibration
+		loopOpts := settings.Loop
+		totalPasses := 1
+		if calibration {
+			totalPasses++
+		}
+		if loopOpts.Crossfade > 0 {
+			totalPasses++
+		}
+		passNumber := 1
 		progressWrapper := func(progress *EncodingProgress) {
 			e.mu.Lock()
 			job.Progress = progress.Progress
@@ -278,16 +615,224 @@ func (e *Encoder) processQueue() {
 			progress.Filename = job.FileInfo.Name
 			progress.CurrentFile = currentJobNum
 			progress.TotalFiles = totalJobs
+			progress.PassNumber = passNumber
+			progress.TotalPasses = totalPasses
 
 			if e.progressCb != nil {
 				e.progressCb(progress)
 			}
 		}
 
-		// Run encoding with duration for progress calculation (add black intro to total duration)
-		totalDuration := job.FileInfo.DurationSeconds + float64(blackIntro)
+		// Two-pass loudness normalization: measure the source track first
+		loudnessTarget := settings.LoudnessTarget
+		audio := job.Preset.EffectiveAudio(opts.Audio)
+		if loudnessTarget != 0 && audio.Mode != preset.AudioModeNone && len(job.FileInfo.AudioTracks) == 0 {
+			// Unknown or missing audio tracks can't be measured
+			fmt.Printf("[Loudness] %s: no audio track found, encoding without normalization\n", job.FileInfo.Name)
+			e.mu.Lock()
+			job.Loudness = &LoudnessReport{TargetLUFS: loudnessTarget, Skipped: "오디오 트랙을 찾을 수 없습니다"}
+			e.mu.Unlock()
+		} else if loudnessTarget != 0 && audio.Mode != preset.AudioModeNone {
+			totalPasses++
+			if e.progressCb != nil {
+				e.progressCb(&EncodingProgress{
+					Filename:    job.FileInfo.Name,
+					Status:      StatusEncoding,
+					CurrentFile: currentJobNum,
+					TotalFiles:  totalJobs,
+					PassNumber:  1,
+					TotalPasses: totalPasses,
+				})
+			}
+
+			track := audio.Track
+			if track < 0 || track >= len(job.FileInfo.AudioTracks) {
+				track = 0
+			}
+			measured, measureErr := e.ffmpeg.MeasureLoudness(e.cancelCtx, job.InputPath, track, opts, job.Options.TrimmedDuration(job.FileInfo.DurationSeconds), loudnessTarget)
+			if measureErr != nil {
+				fmt.Printf("[Loudness] %s: %v, encoding without normalization\n", job.FileInfo.Name, measureErr)
+				totalPasses--
+				e.mu.Lock()
+				job.Loudness = &LoudnessReport{TargetLUFS: loudnessTarget, Skipped: measureErr.Error()}
+				e.mu.Unlock()
+			} else {
+				passNumber++
+				opts.Loudnorm = loudnormFor(loudnessTarget, measured)
+				e.mu.Lock()
+				job.Loudness = &LoudnessReport{TargetLUFS: loudnessTarget, Input: measured}
+				e.mu.Unlock()
+			}
+		}
+
+		args := job.Preset.ToFFmpegArgsWithOptions(job.InputPath, job.OutputPath, sourceInfo, opts)
+
+		// Run encoding with duration for progress calculation (add pre-roll and post-roll to total duration)
+		_, _, outputFPS, _ := job.Preset.EffectiveValues(sourceInfo)
+		introFrames := preRoll.FrameCount(outputFPS)
+		outroFrames := postRoll.FrameCount(outputFPS)
+		totalDuration := job.Options.TrimmedDuration(job.FileInfo.DurationSeconds)
+		if outputFPS > 0 {
+			totalDuration += float64(introFrames+outroFrames) / outputFPS
+		}
+		encodeStart := time.Now()
 		result, err := e.ffmpeg.Encode(e.cancelCtx, args, totalDuration, progressWrapper)
 
+		// Fall back to the software decode path when the hardware decoder rejects the stream
+		usedHWDecode := opts.HWDecode && preset.SupportsHWDecode(encoderID, sourceInfo.Codec)
+		if usedHWDecode && e.cancelCtx.Err() == nil && (err != nil || !result.Success) {
+			fmt.Printf("[HWAccel] %s: hardware decode failed, retrying with software decode\n", job.FileInfo.Name)
+			opts.HWDecode = false
+			args = job.Preset.ToFFmpegArgsWithOptions(job.InputPath, job.OutputPath, sourceInfo, opts)
+			result, err = e.ffmpeg.Encode(e.cancelCtx, args, totalDuration, progressWrapper)
+		}
+		encodeSeconds := time.Since(encodeStart).Seconds()
+
+		// Measure the normalized output for the before/after report
+		if err == nil && result.Success && job.Loudness != nil && job.Loudness.Input != nil {
+			if measured, measureErr := e.ffmpeg.MeasureLoudness(e.cancelCtx, job.OutputPath, 0, preset.EncodeOptions{}, 0, loudnessTarget); measureErr == nil {
+				e.mu.Lock()
+				job.Loudness.Output = measured
+				e.mu.Unlock()
+				fmt.Printf("[Loudness] %s: %.1f LUFS → %.1f LUFS (target %.1f)\n", job.FileInfo.Name, job.Loudness.Input.I, measured.I, loudnessTarget)
+			}
+		}
+
+		// Probe the output packets for the checks below and hash the file in the same read.
+		// FFmpeg rewrites the Matroska header after the last cluster, so the output can't
+		// be hashed while it is written.
+		outputSum := ""
+		if err == nil && result.Success {
+			packets, sum, probeErr := probeOutput(job.OutputPath)
+			if probeErr != nil {
+				fmt.Printf("[Verify] %s: packet scan failed: %v\n", job.FileInfo.Name, probeErr)
+			} else {
+				outputSum = sum
+				e.mu.Lock()
+				job.packets = packets
+				e.mu.Unlock()
+			}
+		}
+
+		// Verify peak bitrate against the VBV cap so player-unsafe outputs are flagged
+		if err == nil && result.Success && job.packets != nil {
+			if maxBitrate, bufSize := job.Preset.VBVFor(sourceInfo); maxBitrate > 0 {
+				check, verifyErr := VerifyPeakBitrate(job.packets, maxBitrate, float64(bufSize)/float64(maxBitrate))
+				if verifyErr != nil {
+					fmt.Printf("[Verify] %s: peak bitrate check failed: %v\n", job.FileInfo.Name, verifyErr)
+				} else {
+					if !check.Passed {
+						fmt.Printf("[Verify] %s: peak bitrate %dkbps exceeds cap %dkbps at %.1fs\n", job.FileInfo.Name, check.PeakKbps, check.MaxKbps, check.PeakAt)
+					}
+					e.mu.Lock()
+					job.BitrateCheck = check
+					e.mu.Unlock()
+				}
+			}
+		}
+
+		// Write the calibration variant from the same settings, leaving the clean output untouched
+		if err == nil && result.Success && calibration && e.cancelCtx.Err() == nil {
+			passNumber++
+			overlayOpts := opts
+			overlayOpts.Overlay = &preset.Overlay{
+				Filename: job.FileInfo.Name,
+				Label:    job.Options.ScreenLabel,
+				FontFile: overlayFontFile(),
+			}
+			overlayPath := calibrationPath(job.OutputPath)
+			overlayArgs := job.Preset.ToFFmpegArgsWithOptions(job.InputPath, overlayPath, sourceInfo, overlayOpts)
+			if overlayResult, overlayErr := e.ffmpeg.Encode(e.cancelCtx, overlayArgs, totalDuration, progressWrapper); overlayErr != nil || !overlayResult.Success {
+				fmt.Printf("[Calibration] %s: calibration output failed\n", job.FileInfo.Name)
+			} else {
+				e.mu.Lock()
+				job.CalibrationPath = overlayPath
+				e.mu.Unlock()
+			}
+		}
+
+		// Write the seamless loop variant and check the loop seams
+		hasAudio := audio.Mode != preset.AudioModeNone && len(job.FileInfo.AudioTracks) > 0
+		if err == nil && result.Success && loopOpts.Crossfade > 0 && e.cancelCtx.Err() == nil {
+			passNumber++
+			loopEncodeOpts := opts
+			loopEncodeOpts.LoopCrossfade = loopOpts.Crossfade
+			path := loopPath(job.OutputPath)
+			loopArgs := job.Preset.ToFFmpegArgsWithOptions(job.InputPath, path, sourceInfo, loopEncodeOpts)
+			if loopResult, loopErr := e.ffmpeg.Encode(e.cancelCtx, loopArgs, totalDuration-loopOpts.Crossfade, progressWrapper); loopErr != nil || !loopResult.Success {
+				fmt.Printf("[Loop] %s: seamless loop output failed\n", job.FileInfo.Name)
+			} else {
+				e.mu.Lock()
+				job.LoopPath = path
+				e.mu.Unlock()
+			}
+		}
+		if err == nil && result.Success && loopOpts.Verify && e.cancelCtx.Err() == nil {
+			for _, target := range []struct {
+				path  string
+				check **LoopCheck
+			}{
+				{job.OutputPath, &job.LoopCheck},
+				{job.LoopPath, &job.LoopPathCheck},
+			} {
+				if target.path == "" {
+					continue
+				}
+				check, verifyErr := e.ffmpeg.VerifyLoop(e.cancelCtx, target.path, hasAudio)
+				if verifyErr != nil {
+					fmt.Printf("[Loop] %s: loop check failed: %v\n", filepath.Base(target.path), verifyErr)
+					continue
+				}
+				if !check.Passed {
+					fmt.Printf("[Loop] %s: visible loop seam (SSIM %.3f, level jump %.1fdB)\n", filepath.Base(target.path), check.SSIM, check.LevelJumpDB)
+				}
+				e.mu.Lock()
+				*target.check = check
+				e.mu.Unlock()
+			}
+		}
+
+		// Verify the pre-roll and post-roll added exactly the requested frames
+		if err == nil && result.Success && job.packets != nil && introFrames+outroFrames > 0 {
+			check, verifyErr := VerifyFrameCount(job.packets, job.FileInfo, job.Options, outputFPS, introFrames, outroFrames, rollFrameTolerance(preRoll, postRoll))
+			if verifyErr != nil {
+				fmt.Printf("[Verify] %s: frame count check failed: %v\n", job.FileInfo.Name, verifyErr)
+			} else {
+				if !check.Passed {
+					fmt.Printf("[Verify] %s: %d frames, expected %d\n", job.FileInfo.Name, check.ActualFrames, check.ExpectedFrames)
+				}
+				e.mu.Lock()
+				job.FrameCheck = check
+				e.mu.Unlock()
+			}
+		}
+
+		// Record checksums so copies on player drives can be verified
+		if err == nil && result.Success && e.cancelCtx.Err() == nil {
+			for _, path := range []string{job.OutputPath, job.CalibrationPath, job.LoopPath} {
+				if path == "" {
+					continue
+				}
+				var sum string
+				var sumErr error
+				if path == job.OutputPath && outputSum != "" {
+					sum, sumErr = outputSum, writeChecksumFile(path, outputSum)
+				} else {
+					// Variants are not probed, so they are read once for their checksum
+					sum, sumErr = writeChecksum(path)
+				}
+				if sumErr != nil {
+					fmt.Printf("[Checksum] %s: checksum not written: %v\n", filepath.Base(path), sumErr)
+					continue
+				}
+				if path == job.OutputPath {
+					e.mu.Lock()
+					job.SHA256 = sum
+					e.mu.Unlock()
+				}
+			}
+		}
+
 		// Check for cancellation
 		if e.cancelCtx.Err() == context.Canceled {
 			e.mu.Lock()
@@ -317,6 +862,26 @@ func (e *Encoder) processQueue() {
 		} else {
 			job.Status = StatusCompleted
 			job.Progress = 100
+			job.EncodeSeconds = encodeSeconds
+			if stat, statErr := os.Stat(job.OutputPath); statErr == nil {
+				job.OutputSize = stat.Size()
+			}
+			maxBitrate, _ := job.Preset.VBVFor(sourceInfo)
+			job.Settings = &OutputSettings{
+				Preset:         job.Preset.Name,
+				Encoder:        encoderID,
+				Quality:        opts.Quality,
+				BitDepth:       opts.BitDepth,
+				HDRMode:        opts.HDRMode,
+				MaxBitrate:     maxBitrate,
+				AudioMode:      audio.Mode,
+				LoudnessTarget: loudnessTarget,
+				AudioOffsetMs:  opts.AudioOffsetMs,
+				TrimStart:      opts.TrimStart,
+				TrimEnd:        opts.TrimEnd,
+				PreRollFrames:  introFrames,
+				PostRollFrames: outroFrames,
+			}
 
 			if e.completeCb != nil {
 				go e.completeCb(result, job)
@@ -327,9 +892,22 @@ func (e *Encoder) processQueue() {
 		e.mu.Unlock()
 	}
 
+	// Outputs of a batch play in sync, so their keyframes must line up for seeking
+	e.verifyKeyframeAlignment()
+
+	// The player loads the batch from the manifest and refuses mismatched files
+	manifestPaths, manifestErr := e.writeManifests()
+	if manifestErr != nil {
+		fmt.Printf("[Manifest] manifest not written: %v\n", manifestErr)
+	}
+
 	// All jobs completed
+	report := e.buildReport(started, manifestPaths)
+
 	e.mu.Lock()
 	e.isRunning = false
+	e.manifestPaths = manifestPaths
+	e.report = report
 
 	// Count completed and failed
 	completed := 0
@@ -366,3 +944,33 @@ func (e *Encoder) GetCurrentProgress() *EncodingProgress {
 		Status:      job.Status,
 	}
 }
+
+// presetSourceInfo converts probed file info to the source properties used by presets
+func presetSourceInfo(info *fileinfo.FileInfo) *preset.FileInfo {
+	// Only a fully read track list makes an empty list mean no audio; unknown
+	// track lists stay nil so the audio is encoded as before
+	var audioTracks []preset.AudioTrack
+	if info.AudioTracksKnown {
+		audioTracks = make([]preset.AudioTrack, 0, len(info.AudioTracks))
+		for _, track := range info.AudioTracks {
+			audioTracks = append(audioTracks, preset.AudioTrack{Codec: track.Codec, Channels: track.Channels})
+		}
+	}
+
+	return &preset.FileInfo{
+		Width:          info.Width,
+		Height:         info.Height,
+		Framerate:      info.Framerate,
+		Duration:       info.DurationSeconds,
+		Codec:          info.Codec,
+		BitDepth:       info.BitDepth,
+		ColorPrimaries: info.ColorPrimaries,
+		ColorTransfer:  info.ColorTransfer,
+		ColorSpace:     info.ColorSpace,
+		ColorRange:     info.ColorRange,
+		MasterDisplay:  info.MasterDisplay,
+		MaxCLL:         info.MaxCLL,
+		MaxFALL:        info.MaxFALL,
+		AudioTracks:    audioTracks,
+	}
+}
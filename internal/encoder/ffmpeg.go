@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"syncLauperVideoConverter/internal/cmdutil"
@@ -66,6 +67,9 @@ func DefaultFFmpegConfig() FFmpegConfig {
 // FFmpeg represents an FFmpeg CLI wrapper
 type FFmpeg struct {
 	config FFmpegConfig
+
+	hwMu       sync.Mutex
+	hwEncoders []HWEncoder // detected encoders (nil = not detected yet)
 }
 
 // NewFFmpeg creates a new FFmpeg instance
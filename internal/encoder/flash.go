@@ -0,0 +1,157 @@
+package encoder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"syncLauperVideoConverter/internal/cmdutil"
+	"syncLauperVideoConverter/internal/fileinfo"
+)
+
+// Flash detection settings
+const (
+	flashDefaultSeconds = 30   // search window when none is given
+	flashMinJump        = 50.0 // minimum mean luma rise (8-bit scale) between frames
+	flashMaxFrames      = 5    // a flash falls back within this many frames; a cut to a bright scene doesn't
+)
+
+// FlashSync is the sync flash found in a file: the first frame where mean luma jumps
+// up and falls back shortly after
+type FlashSync struct {
+	Path  string  `json:"path"`
+	Frame int     `json:"frame"`           // frame index of the flash
+	Time  float64 `json:"time"`            // presentation time of the flash in seconds
+	Jump  float64 `json:"jump"`            // mean luma rise over the previous frame (0-255)
+	Error string  `json:"error,omitempty"` // detection error for this file ("" = found)
+}
+
+// DetectSyncFlashes finds the sync flash in the first seconds of each file
+func (f *FFmpeg) DetectSyncFlashes(ctx context.Context, files []*fileinfo.FileInfo, seconds float64) ([]FlashSync, error) {
+	if seconds <= 0 {
+		seconds = flashDefaultSeconds
+	}
+
+	results := make([]FlashSync, 0, len(files))
+	for _, file := range files {
+		flash, err := f.detectFlash(ctx, file.Path, seconds)
+		if err != nil {
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+			flash.Error = err.Error()
+		}
+		results = append(results, flash)
+	}
+	return results, nil
+}
+
+// FlashTrims converts detected flashes into start trims in seconds, so the flash lands
+// on the same frame in every output (files without a flash are left untrimmed)
+func FlashTrims(flashes []FlashSync) map[string]float64 {
+	earliest := math.Inf(1)
+	for _, fl := range flashes {
+		if fl.Error == "" && fl.Time < earliest {
+			earliest = fl.Time
+		}
+	}
+
+	trims := make(map[string]float64, len(flashes))
+	for _, fl := range flashes {
+		if fl.Error == "" {
+			trims[fl.Path] = fl.Time - earliest
+		}
+	}
+	return trims
+}
+
+// detectFlash reads per-frame mean luma with signalstats and returns the first short
+// flash found by findFlash
+func (f *FFmpeg) detectFlash(ctx context.Context, path string, seconds float64) (FlashSync, error) {
+	flash := FlashSync{Path: path}
+
+	// Downscaled gray frames keep the analysis fast and the luma on an 8-bit scale
+	args := []string{
+		"-hide_banner",
+		"-v", "error",
+		"-t", fmt.Sprintf("%.3f", seconds),
+		"-i", path,
+		"-map", "0:v:0",
+		"-vf", "scale=320:-2,format=gray,signalstats,metadata=print:key=lavfi.signalstats.YAVG:file=-",
+		"-f", "null", "-",
+	}
+
+	cmd := exec.CommandContext(ctx, f.config.ExecutablePath, args...)
+	cmdutil.HideWindow(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return flash, fmt.Errorf("밝기 분석 실패: %v", err)
+	}
+
+	sample, jump, ok := findFlash(parseLumaSamples(output))
+	if !ok {
+		return flash, fmt.Errorf("동기 플래시를 찾을 수 없습니다")
+	}
+	flash.Frame = sample.frame
+	flash.Time = sample.pts
+	flash.Jump = jump
+	return flash, nil
+}
+
+// lumaSample is the mean luma of one analyzed frame
+type lumaSample struct {
+	frame int
+	pts   float64 // seconds
+	y     float64 // 0-255
+}
+
+// parseLumaSamples reads metadata=print output, which alternates
+// "frame:N pts:P pts_time:T" and "lavfi.signalstats.YAVG=V" lines
+func parseLumaSamples(output []byte) []lumaSample {
+	var samples []lumaSample
+	frame := -1
+	pts := 0.0
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "frame:") {
+			frame++
+			for _, field := range strings.Fields(line) {
+				if value, ok := strings.CutPrefix(field, "pts_time:"); ok {
+					pts, _ = strconv.ParseFloat(value, 64)
+				}
+			}
+			continue
+		}
+		value, ok := strings.CutPrefix(line, "lavfi.signalstats.YAVG=")
+		if !ok {
+			continue
+		}
+		if y, err := strconv.ParseFloat(value, 64); err == nil {
+			samples = append(samples, lumaSample{frame: frame, pts: pts, y: y})
+		}
+	}
+	return samples
+}
+
+// findFlash returns the first frame whose mean luma rises by at least flashMinJump
+// and falls back by half the rise within flashMaxFrames
+func findFlash(samples []lumaSample) (flash lumaSample, jump float64, ok bool) {
+	for i := 1; i < len(samples); i++ {
+		jump = samples[i].y - samples[i-1].y
+		if jump < flashMinJump {
+			continue
+		}
+		for j := i + 1; j < len(samples) && j <= i+flashMaxFrames; j++ {
+			if samples[i].y-samples[j].y >= jump/2 {
+				return samples[i], jump, true
+			}
+		}
+	}
+	return lumaSample{}, 0, false
+}
@@ -0,0 +1,56 @@
+package encoder
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// metadataOutput builds metadata=print output for frames with the given mean luma at 25 fps
+func metadataOutput(lumas ...float64) []byte {
+	var b strings.Builder
+	for i, y := range lumas {
+		fmt.Fprintf(&b, "frame:%d    pts:%d    pts_time:%g\n", i, i*512, float64(i)*0.04)
+		fmt.Fprintf(&b, "lavfi.signalstats.YAVG=%.3f\n", y)
+	}
+	return []byte(b.String())
+}
+
+func TestParseLumaSamples(t *testing.T) {
+	samples := parseLumaSamples(metadataOutput(16, 20.5, 235))
+	if len(samples) != 3 {
+		t.Fatalf("got %d samples, want 3", len(samples))
+	}
+	last := samples[2]
+	if last.frame != 2 || last.pts != 0.08 || last.y != 235 {
+		t.Errorf("last sample = %+v, want frame 2 at 0.08s with luma 235", last)
+	}
+}
+
+func TestFindFlash(t *testing.T) {
+	tests := []struct {
+		name  string
+		lumas []float64
+		frame int
+		found bool
+	}{
+		{"single frame flash", []float64{16, 16, 235, 16, 16}, 2, true},
+		{"three frame flash", []float64{30, 30, 30, 200, 210, 205, 32, 30}, 3, true},
+		{"cut to bright scene is ignored", []float64{16, 16, 180, 180, 180, 180, 180, 180, 180}, 0, false},
+		{"flash after a bright cut", []float64{16, 180, 180, 180, 180, 180, 180, 180, 40, 40, 235, 40}, 10, true},
+		{"small rise is ignored", []float64{100, 140, 100}, 0, false},
+		{"flash on the last frame is unconfirmed", []float64{16, 16, 235}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample, _, ok := findFlash(parseLumaSamples(metadataOutput(tt.lumas...)))
+			if ok != tt.found {
+				t.Fatalf("found = %v, want %v", ok, tt.found)
+			}
+			if ok && sample.frame != tt.frame {
+				t.Errorf("frame = %d, want %d", sample.frame, tt.frame)
+			}
+		})
+	}
+}
@@ -1,12 +1,11 @@
 package encoder
 
 import (
-	"context"
 	"fmt"
 	"os/exec"
 	"runtime"
 	"strings"
-	"time"
+	"sync"
 
 	"syncLauperVideoConverter/internal/cmdutil"
 )
@@ -18,25 +17,85 @@ type HWEncoder struct {
 	Description string `json:"description"` // Short description
 	Available   bool   `json:"available"`   // Whether the encoder is available
 	Priority    int    `json:"priority"`    // Higher = preferred
+	Device      string `json:"device"`      // DRM render node for VAAPI/QSV ("" = default)
+
+	Capabilities *EncoderCapabilities `json:"capabilities,omitempty"` // Probed limits (nil = unknown)
 }
 
-// GetAvailableHWEncoders returns all available HEVC hardware encoders
+// GetAvailableHWEncoders returns all available HEVC hardware encoders.
+// Results are cached in the config dir, keyed by ffmpeg path, ffmpeg version and
+// GPU/driver fingerprint, so the runtime tests only run when the setup changes.
 func (f *FFmpeg) GetAvailableHWEncoders() []HWEncoder {
-	// Define all known HEVC hardware encoders
+	f.hwMu.Lock()
+	defer f.hwMu.Unlock()
+
+	if f.hwEncoders == nil {
+		key := f.currentHWCacheKey()
+		if cached, ok := loadHWEncoderCache(key); ok {
+			f.hwEncoders = cached
+		} else {
+			f.detectAndCacheHWEncoders(key)
+		}
+	}
+
+	// Return a copy so callers can't modify the cached list
+	return append([]HWEncoder(nil), f.hwEncoders...)
+}
+
+// RedetectHWEncoders ignores the cache and runs encoder detection again
+func (f *FFmpeg) RedetectHWEncoders() []HWEncoder {
+	f.hwMu.Lock()
+	defer f.hwMu.Unlock()
+
+	f.detectAndCacheHWEncoders(f.currentHWCacheKey())
+	return append([]HWEncoder(nil), f.hwEncoders...)
+}
+
+// detectAndCacheHWEncoders runs detection and stores the result (caller holds hwMu)
+func (f *FFmpeg) detectAndCacheHWEncoders(key hwCacheKey) {
+	f.hwEncoders = f.detectHWEncoders()
+
+	if err := saveHWEncoderCache(key, f.hwEncoders); err != nil {
+		fmt.Printf("[HWAccel] failed to save encoder cache: %v\n", err)
+	}
+}
+
+// detectHWEncoders lists encoders known to FFmpeg and runtime-tests them in parallel
+func (f *FFmpeg) detectHWEncoders() []HWEncoder {
+	// Define all known HEVC hardware encoders, one per render device where applicable
 	allEncoders := getKnownHWEncoders()
+	if runtime.GOOS == "linux" {
+		allEncoders = expandRenderDevices(allEncoders, ListRenderDevices())
+	}
 
 	// Get list of encoders from FFmpeg
 	availableEncoders := f.getFFmpegEncoders()
 
-	// Check which encoders are available and actually work
-	var result []HWEncoder
-	for _, enc := range allEncoders {
-		if !availableEncoders[enc.ID] {
+	// Runtime test: verify each listed encoder actually works on this hardware
+	passed := make([]bool, len(allEncoders))
+	var wg sync.WaitGroup
+	for i, enc := range allEncoders {
+		encoderID, device := splitEncoderID(enc.ID)
+		if !availableEncoders[encoderID] {
 			continue
 		}
-		// Runtime test: verify the encoder actually works on this hardware
-		if err := f.TestEncoder(enc.ID); err != nil {
-			fmt.Printf("[HWAccel] %s listed but failed runtime test: %v\n", enc.ID, err)
+		wg.Add(1)
+		go func(i int, encoderID string, device string) {
+			defer wg.Done()
+			if err := f.TestEncoder(encoderID, device); err != nil {
+				fmt.Printf("[HWAccel] %s listed but failed runtime test: %v\n", allEncoders[i].ID, err)
+				return
+			}
+			allEncoders[i].Capabilities = f.ProbeCapabilities(encoderID, device)
+			passed[i] = true
+		}(i, encoderID, device)
+	}
+	wg.Wait()
+
+	// Keep the known-encoder order regardless of test completion order
+	var result []HWEncoder
+	for i, enc := range allEncoders {
+		if !passed[i] {
 			continue
 		}
 		enc.Available = true
@@ -45,11 +104,12 @@ func (f *FFmpeg) GetAvailableHWEncoders() []HWEncoder {
 
 	// Always add software encoder as fallback
 	result = append(result, HWEncoder{
-		ID:          "libx265",
-		Name:        "Software (x265)",
-		Description: "CPU 인코딩 - 느리지만 호환성 최고",
-		Available:   true,
-		Priority:    0,
+		ID:           "libx265",
+		Name:         "Software (x265)",
+		Description:  "CPU 인코딩 - 느리지만 호환성 최고",
+		Available:    true,
+		Priority:     0,
+		Capabilities: f.ProbeCapabilities("libx265", ""),
 	})
 
 	return result
@@ -146,37 +206,10 @@ func (f *FFmpeg) getFFmpegEncoders() map[string]bool {
 	return result
 }
 
-// TestEncoder tests if a hardware encoder actually works by running a quick 1-frame encode
-func (f *FFmpeg) TestEncoder(encoderID string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	// Build test encode command: generate 1 frame of null video and encode it
-	args := []string{
-		"-hide_banner",
-		"-loglevel", "error",
-	}
-
-	// Add pre-input args for hardware encoders
-	switch encoderID {
-	case "hevc_qsv":
-		args = append(args, "-init_hw_device", "qsv=hw", "-filter_hw_device", "hw")
-	}
-
-	args = append(args,
-		"-f", "lavfi", "-i", "nullsrc=s=256x256:d=0.1",
-		"-c:v", encoderID,
-		"-frames:v", "1",
-		"-f", "null", "-",
-	)
-
-	cmd := exec.CommandContext(ctx, f.config.ExecutablePath, args...)
-	cmdutil.HideWindow(cmd)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%s 인코더 테스트 실패: %s", encoderID, strings.TrimSpace(string(output)))
-	}
-	return nil
+// TestEncoder tests if a hardware encoder actually works by running a quick 1-frame encode.
+// device selects the DRM render node for VAAPI/QSV ("" = default).
+func (f *FFmpeg) TestEncoder(encoderID string, device string) error {
+	return f.probeEncode(encoderID, device, encodeProbe{Width: 256, Height: 256})
 }
 
 // GetBestEncoder returns the best available encoder (highest priority)
@@ -0,0 +1,171 @@
+package encoder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"syncLauperVideoConverter/internal/cmdutil"
+	"syncLauperVideoConverter/internal/config"
+)
+
+// hwCacheFile is the name of the encoder detection cache in the config dir
+const hwCacheFile = "hwencoders.json"
+
+// hwCacheVersion is bumped whenever the cached HWEncoder data changes shape
+const hwCacheVersion = 5
+
+// hwEncoderCache is the on-disk cache of hardware encoder detection results
+type hwEncoderCache struct {
+	Version        int         `json:"version"`
+	FFmpegPath     string      `json:"ffmpegPath"`
+	FFmpegVersion  string      `json:"ffmpegVersion"`
+	GPUFingerprint string      `json:"gpuFingerprint"`
+	DetectedAt     time.Time   `json:"detectedAt"`
+	Encoders       []HWEncoder `json:"encoders"`
+}
+
+// hwCacheKey identifies the ffmpeg build and GPU/driver setup a detection result is valid for
+type hwCacheKey struct {
+	FFmpegPath     string
+	FFmpegVersion  string
+	GPUFingerprint string
+}
+
+// currentHWCacheKey builds the cache key for the current ffmpeg and GPU setup
+func (f *FFmpeg) currentHWCacheKey() hwCacheKey {
+	version, _ := f.GetVersion()
+	return hwCacheKey{
+		FFmpegPath:     f.config.ExecutablePath,
+		FFmpegVersion:  version,
+		GPUFingerprint: gpuFingerprint(),
+	}
+}
+
+// loadHWEncoderCache returns cached encoders if the cache matches the given key
+func loadHWEncoderCache(key hwCacheKey) ([]HWEncoder, bool) {
+	path, err := config.Path(hwCacheFile)
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var cache hwEncoderCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, false
+	}
+
+	if cache.Version != hwCacheVersion ||
+		cache.FFmpegPath != key.FFmpegPath ||
+		cache.FFmpegVersion != key.FFmpegVersion ||
+		cache.GPUFingerprint != key.GPUFingerprint ||
+		len(cache.Encoders) == 0 {
+		return nil, false
+	}
+
+	return cache.Encoders, true
+}
+
+// saveHWEncoderCache writes detection results to the config dir
+func saveHWEncoderCache(key hwCacheKey, encoders []HWEncoder) error {
+	path, err := config.Path(hwCacheFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(hwEncoderCache{
+		Version:        hwCacheVersion,
+		FFmpegPath:     key.FFmpegPath,
+		FFmpegVersion:  key.FFmpegVersion,
+		GPUFingerprint: key.GPUFingerprint,
+		DetectedAt:     time.Now(),
+		Encoders:       encoders,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first so a crash never leaves a truncated cache
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// gpuFingerprint returns a hash identifying the installed GPUs and their drivers
+func gpuFingerprint() string {
+	var parts []string
+
+	switch runtime.GOOS {
+	case "linux":
+		parts = linuxGPUInfo()
+	case "windows":
+		cmd := exec.Command("powershell", "-NoProfile", "-Command",
+			"Get-CimInstance Win32_VideoController | ForEach-Object { $_.Name + ' ' + $_.DriverVersion }")
+		cmdutil.HideWindow(cmd)
+		if output, err := cmd.Output(); err == nil {
+			parts = strings.Split(strings.TrimSpace(string(output)), "\n")
+		}
+	case "darwin":
+		// VideoToolbox is tied to the chip and the OS version
+		for _, args := range [][]string{
+			{"sysctl", "-n", "machdep.cpu.brand_string"},
+			{"sw_vers", "-productVersion"},
+		} {
+			if output, err := exec.Command(args[0], args[1:]...).Output(); err == nil {
+				parts = append(parts, strings.TrimSpace(string(output)))
+			}
+		}
+	}
+
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	sort.Strings(parts)
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// linuxGPUInfo lists PCI IDs of DRM devices and loaded GPU driver versions from sysfs
+func linuxGPUInfo() []string {
+	var parts []string
+
+	cards, _ := filepath.Glob("/sys/class/drm/card[0-9]*")
+	for _, card := range cards {
+		// Skip connector entries like card0-HDMI-A-1
+		if strings.Contains(filepath.Base(card), "-") {
+			continue
+		}
+		vendor, _ := os.ReadFile(filepath.Join(card, "device", "vendor"))
+		device, _ := os.ReadFile(filepath.Join(card, "device", "device"))
+		driver, _ := os.Readlink(filepath.Join(card, "device", "driver"))
+		parts = append(parts, fmt.Sprintf("%s %s %s %s",
+			filepath.Base(card),
+			strings.TrimSpace(string(vendor)),
+			strings.TrimSpace(string(device)),
+			filepath.Base(driver),
+		))
+	}
+
+	for _, module := range []string{"nvidia", "amdgpu", "i915", "xe"} {
+		if version, err := os.ReadFile(filepath.Join("/sys/module", module, "version")); err == nil {
+			parts = append(parts, module+" "+strings.TrimSpace(string(version)))
+		}
+	}
+
+	return parts
+}
@@ -0,0 +1,43 @@
+package encoder
+
+import "testing"
+
+func TestKeyframeGroups(t *testing.T) {
+	job := func(output, presetName, encoderID string, preRoll int, status string) *EncodingJob {
+		return &EncodingJob{
+			OutputPath: output,
+			Status:     status,
+			Settings:   &OutputSettings{Preset: presetName, Encoder: encoderID, Quality: len(output), PreRollFrames: preRoll},
+		}
+	}
+	jobs := []*EncodingJob{
+		job("a.mkv", "4K", "hevc_nvenc", 0, StatusCompleted),
+		job("b.mkv", "4K", "hevc_nvenc", 0, StatusCompleted),
+		job("requeued.mkv", "FHD", "libx265", 0, StatusCompleted),
+		job("failed.mkv", "4K", "hevc_nvenc", 0, StatusError),
+		job("rolled.mkv", "4K", "hevc_nvenc", 30, StatusCompleted),
+		job("cc.mkv", "4K", "hevc_nvenc", 0, StatusCompleted),
+		{OutputPath: "unsettled.mkv", Status: StatusCompleted},
+	}
+
+	groups := keyframeGroups(jobs)
+	want := [][]string{
+		{"a.mkv", "b.mkv", "cc.mkv"},
+		{"requeued.mkv"},
+		{"rolled.mkv"},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(groups), len(want))
+	}
+	for i, group := range groups {
+		if len(group) != len(want[i]) {
+			t.Errorf("group %d has %d outputs, want %v", i, len(group), want[i])
+			continue
+		}
+		for j, job := range group {
+			if job.OutputPath != want[i][j] {
+				t.Errorf("group %d output %d = %s, want %s", i, j, job.OutputPath, want[i][j])
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package encoder
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"syncLauperVideoConverter/internal/cmdutil"
+	"syncLauperVideoConverter/internal/fileinfo"
+	"syncLauperVideoConverter/internal/preset"
+)
+
+// Loop seam thresholds
+const (
+	loopMinSSIM       = 0.90  // last vs first frame similarity below this is a visible jump
+	loopMaxLevelJump  = 6.0   // dB RMS difference between the end and the start of the audio
+	loopSilenceDB     = -60.0 // RMS levels below this count as silence
+	loopAudioWindowMs = 50    // audio compared on each side of the seam
+)
+
+// LoopOptions controls loop verification and the seamless loop variant
+type LoopOptions struct {
+	Verify    bool    `json:"verify"`    // compare the last and first frame and audio of each output
+	Crossfade float64 `json:"crossfade"` // also write a seamless loop file with this crossfade in seconds (0 = off)
+}
+
+// LoopCheck represents the result of the loop seam verification of an output
+type LoopCheck struct {
+	SSIM        float64 `json:"ssim"`        // last vs first frame (1 = identical)
+	HeadRMSdB   float64 `json:"headRmsDb"`   // audio level at the start
+	TailRMSdB   float64 `json:"tailRmsDb"`   // audio level at the end
+	LevelJumpDB float64 `json:"levelJumpDb"` // level difference across the seam
+	HasAudio    bool    `json:"hasAudio"`
+	Passed      bool    `json:"passed"`
+}
+
+var (
+	ssimAllRegex = regexp.MustCompile(`SSIM .*All:([0-9.]+)`)
+	rmsLevelRe   = regexp.MustCompile(`RMS level dB:\s*(-?[0-9.]+|-inf)`)
+)
+
+// VerifyLoop compares the last frame with the first (SSIM) and the audio level at the
+// end with the level at the start, so jumps at the loop point are flagged
+func (f *FFmpeg) VerifyLoop(ctx context.Context, path string, hasAudio bool) (*LoopCheck, error) {
+	// The tail is reversed so its last frame comes first
+	args := []string{
+		"-hide_banner", "-nostats",
+		"-i", path,
+		"-sseof", "-1", "-i", path,
+		"-filter_complex", "[0:v]trim=end_frame=1,setpts=PTS-STARTPTS[head];" +
+			"[1:v]reverse,trim=end_frame=1,setpts=PTS-STARTPTS[tail];" +
+			"[tail][head]ssim",
+		"-f", "null", "-",
+	}
+	output, err := f.runAnalysis(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("루프 분석 실패: %v", err)
+	}
+	match := ssimAllRegex.FindStringSubmatch(output)
+	if match == nil {
+		return nil, fmt.Errorf("SSIM 결과를 찾을 수 없습니다")
+	}
+
+	check := &LoopCheck{HasAudio: hasAudio}
+	check.SSIM, _ = strconv.ParseFloat(match[1], 64)
+	check.Passed = check.SSIM >= loopMinSSIM
+	if !hasAudio {
+		return check, nil
+	}
+
+	window := fmt.Sprintf("%.3f", float64(loopAudioWindowMs)/1000)
+	head, err := f.audioRMS(ctx, []string{"-t", window, "-i", path})
+	if err != nil {
+		return nil, err
+	}
+	tail, err := f.audioRMS(ctx, []string{"-sseof", "-" + window, "-i", path})
+	if err != nil {
+		return nil, err
+	}
+	// Silence is reported at the floor, which also keeps -inf out of the JSON result
+	check.HeadRMSdB, check.TailRMSdB = math.Max(head, loopSilenceDB), math.Max(tail, loopSilenceDB)
+	check.LevelJumpDB = math.Abs(check.HeadRMSdB - check.TailRMSdB)
+	if check.LevelJumpDB > loopMaxLevelJump {
+		check.Passed = false
+	}
+	return check, nil
+}
+
+// audioRMS measures the overall RMS level of the first audio track of an input
+func (f *FFmpeg) audioRMS(ctx context.Context, input []string) (float64, error) {
+	args := append([]string{"-hide_banner", "-nostats"}, input...)
+	args = append(args, "-map", "0:a:0", "-af", "astats=measure_perchannel=none", "-f", "null", "-")
+	output, err := f.runAnalysis(ctx, args)
+	if err != nil {
+		return 0, fmt.Errorf("오디오 레벨 측정 실패: %v", err)
+	}
+
+	// The overall section is printed last
+	matches := rmsLevelRe.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("오디오 레벨을 찾을 수 없습니다")
+	}
+	value := matches[len(matches)-1][1]
+	if value == "-inf" {
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// runAnalysis runs FFmpeg for a filter that reports on stderr and returns the log
+func (f *FFmpeg) runAnalysis(ctx context.Context, args []string) (string, error) {
+	cmd := exec.CommandContext(ctx, f.config.ExecutablePath, args...)
+	cmdutil.HideWindow(cmd)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// loopPath returns the output path of the seamless loop variant of an output
+func loopPath(outputPath string) string {
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + "_loop.mkv"
+}
+
+// CheckLoopSupport refuses a seamless loop variant that can't be made, so it isn't
+// dropped silently during the batch: with a pre-roll or post-roll the output doesn't
+// start and end on the source, and a source part no longer than two crossfades
+// leaves nothing between the head and the tail
+func (e *Encoder) CheckLoopSupport(p *preset.Preset, s Settings, src *fileinfo.FileInfo, opts JobOptions) error {
+	fade := s.Loop.Crossfade
+	if fade <= 0 {
+		return nil
+	}
+
+	_, _, fps, _ := p.EffectiveValues(presetSourceInfo(src))
+	if s.PreRoll.FrameCount(fps) > 0 || s.PostRoll.FrameCount(fps) > 0 {
+		return fmt.Errorf("루프 크로스페이드는 프리롤/포스트롤과 함께 사용할 수 없습니다")
+	}
+	if opts.TrimmedDuration(src.DurationSeconds) <= 2*fade {
+		return fmt.Errorf("%s: 영상 길이가 루프 크로스페이드(%.1f초)의 두 배보다 짧습니다", src.Name, fade)
+	}
+	return nil
+}
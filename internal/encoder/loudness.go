@@ -0,0 +1,128 @@
+package encoder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"syncLauperVideoConverter/internal/cmdutil"
+	"syncLauperVideoConverter/internal/preset"
+)
+
+// Loudness normalization defaults (EBU R128)
+const (
+	loudnessTruePeak = -1.0 // dBTP ceiling
+	loudnessRange    = 11.0 // LU
+)
+
+// LoudnessMeasurement is the loudnorm analysis of an audio track
+type LoudnessMeasurement struct {
+	I      float64 `json:"i"`      // integrated loudness in LUFS
+	TP     float64 `json:"tp"`     // true peak in dBTP
+	LRA    float64 `json:"lra"`    // loudness range in LU
+	Thresh float64 `json:"thresh"` // gating threshold in LUFS
+	Offset float64 `json:"offset"` // gain offset to the target
+}
+
+// LoudnessReport records the loudness of a job before and after normalization
+type LoudnessReport struct {
+	TargetLUFS float64              `json:"targetLufs"`
+	Input      *LoudnessMeasurement `json:"input"`             // nil = not normalized
+	Output     *LoudnessMeasurement `json:"output,omitempty"`  // nil = output not measured
+	Skipped    string               `json:"skipped,omitempty"` // why the job wasn't normalized
+}
+
+// loudnormJSON mirrors the print_format=json output of loudnorm (values are strings)
+type loudnormJSON struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	TargetOffset string `json:"target_offset"`
+}
+
+// MeasureLoudness runs the first loudnorm pass over an audio track and returns the measured values.
+// The trim range and audio offset of opts are applied with the same seek and filters as the
+// encode, so the measured samples are the normalized ones. duration is the trimmed length.
+func (f *FFmpeg) MeasureLoudness(ctx context.Context, path string, track int, opts preset.EncodeOptions, duration float64, targetI float64) (*LoudnessMeasurement, error) {
+	args := []string{
+		"-hide_banner",
+		"-nostats",
+	}
+	args = append(args, loudnessMeasureArgs(path, track, opts, duration, targetI)...)
+
+	cmd := exec.CommandContext(ctx, f.config.ExecutablePath, args...)
+	cmdutil.HideWindow(cmd)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("라우드니스 측정 실패: %v", err)
+	}
+
+	// The JSON block is printed last
+	text := string(output)
+	start := strings.LastIndex(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("라우드니스 측정 결과를 찾을 수 없습니다")
+	}
+
+	var raw loudnormJSON
+	if err := json.Unmarshal([]byte(text[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("라우드니스 측정 결과를 해석할 수 없습니다: %v", err)
+	}
+
+	// Silent tracks report -inf, which can't be normalized
+	var m LoudnessMeasurement
+	for _, field := range []struct {
+		value string
+		dst   *float64
+	}{
+		{raw.InputI, &m.I},
+		{raw.InputTP, &m.TP},
+		{raw.InputLRA, &m.LRA},
+		{raw.InputThresh, &m.Thresh},
+		{raw.TargetOffset, &m.Offset},
+	} {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field.value), 64)
+		if err != nil || v < -1000 || v > 1000 {
+			return nil, fmt.Errorf("무음 트랙은 라우드니스를 맞출 수 없습니다")
+		}
+		*field.dst = v
+	}
+
+	return &m, nil
+}
+
+// loudnessMeasureArgs returns the input and filter arguments of the measuring pass
+func loudnessMeasureArgs(path string, track int, opts preset.EncodeOptions, duration float64, targetI float64) []string {
+	var filters []string
+	if opts.AudioOffsetMs != 0 {
+		filters = append(filters, preset.AudioOffsetFilters(opts.AudioOffsetMs, duration)...)
+	}
+	filters = append(filters, fmt.Sprintf("loudnorm=I=%.1f:TP=%.1f:LRA=%.1f:print_format=json", targetI, loudnessTruePeak, loudnessRange))
+
+	args := preset.TrimArgs(opts.TrimStart, opts.TrimEnd)
+	return append(args,
+		"-i", path,
+		"-map", fmt.Sprintf("0:a:%d", track),
+		"-af", strings.Join(filters, ","),
+		"-f", "null", "-",
+	)
+}
+
+// loudnormFor builds the second-pass loudnorm options from a measurement
+func loudnormFor(targetI float64, m *LoudnessMeasurement) *preset.Loudnorm {
+	return &preset.Loudnorm{
+		TargetI:        targetI,
+		TargetTP:       loudnessTruePeak,
+		TargetLRA:      loudnessRange,
+		MeasuredI:      m.I,
+		MeasuredTP:     m.TP,
+		MeasuredLRA:    m.LRA,
+		MeasuredThresh: m.Thresh,
+		Offset:         m.Offset,
+	}
+}
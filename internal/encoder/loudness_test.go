@@ -0,0 +1,51 @@
+package encoder
+
+import (
+	"strings"
+	"testing"
+
+	"syncLauperVideoConverter/internal/preset"
+)
+
+func TestLoudnessMeasureArgs(t *testing.T) {
+	loudnorm := "loudnorm=I=-23.0:TP=-1.0:LRA=11.0:print_format=json"
+	tests := []struct {
+		name     string
+		opts     preset.EncodeOptions
+		duration float64
+		want     string
+	}{
+		{
+			"whole file",
+			preset.EncodeOptions{},
+			0,
+			"-i in.mov -map 0:a:1 -af " + loudnorm + " -f null -",
+		},
+		{
+			"trimmed",
+			preset.EncodeOptions{TrimStart: 2, TrimEnd: 12},
+			10,
+			"-ss 2.000 -t 10.000 -i in.mov -map 0:a:1 -af " + loudnorm + " -f null -",
+		},
+		{
+			"audio later, trimmed",
+			preset.EncodeOptions{TrimStart: 2, TrimEnd: 12, AudioOffsetMs: 40},
+			10,
+			"-ss 2.000 -t 10.000 -i in.mov -map 0:a:1 -af adelay=delays=40:all=1,atrim=end=10.000," + loudnorm + " -f null -",
+		},
+		{
+			"audio earlier",
+			preset.EncodeOptions{AudioOffsetMs: -250},
+			30,
+			"-i in.mov -map 0:a:1 -af atrim=start=0.250,asetpts=PTS-STARTPTS,apad=whole_dur=30.000," + loudnorm + " -f null -",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(loudnessMeasureArgs("in.mov", 1, tt.opts, tt.duration, -23), " ")
+			if got != tt.want {
+				t.Errorf("args:\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
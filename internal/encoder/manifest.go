@@ -0,0 +1,147 @@
+package encoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"syncLauperVideoConverter/internal/fileinfo"
+)
+
+// Manifest file written next to the outputs of a batch
+const (
+	manifestName    = "synclauper_manifest.json"
+	manifestVersion = 1
+)
+
+// OutputSettings represents the settings an output was encoded with
+type OutputSettings struct {
+	Preset         string  `json:"preset"`
+	Encoder        string  `json:"encoder"`
+	Quality        int     `json:"quality"` // CRF (0 = encoder default)
+	BitDepth       int     `json:"bitDepth"`
+	HDRMode        string  `json:"hdrMode,omitempty"`
+	MaxBitrate     int     `json:"maxBitrate,omitempty"` // VBV cap in kbps
+	AudioMode      string  `json:"audioMode"`
+	LoudnessTarget float64 `json:"loudnessTarget,omitempty"` // LUFS
+	AudioOffsetMs  int     `json:"audioOffsetMs,omitempty"`
+	TrimStart      float64 `json:"trimStart,omitempty"` // seconds
+	TrimEnd        float64 `json:"trimEnd,omitempty"`   // seconds
+	PreRollFrames  int     `json:"preRollFrames,omitempty"`
+	PostRollFrames int     `json:"postRollFrames,omitempty"`
+}
+
+// ManifestEntry represents one output of the batch
+type ManifestEntry struct {
+	Screen   int            `json:"screen"` // 1-based position in the batch
+	Label    string         `json:"label,omitempty"`
+	Path     string         `json:"path"` // file name; outputs sit next to their manifest
+	Source   string         `json:"source"`
+	Duration float64        `json:"duration"` // seconds
+	Frames   int            `json:"frames"`
+	FPS      float64        `json:"fps"`
+	Width    int            `json:"width"`
+	Height   int            `json:"height"`
+	SHA256   string         `json:"sha256"`
+	Settings OutputSettings `json:"settings"`
+}
+
+// Manifest represents the player configuration of a batch
+type Manifest struct {
+	Version int             `json:"version"`
+	Created string          `json:"created"`
+	Matched bool            `json:"matched"` // all outputs share frame count, fps, resolution and keyframes
+	Outputs []ManifestEntry `json:"outputs"`
+}
+
+// writeManifests writes a manifest of the completed outputs into every folder that
+// holds some of them and returns their paths in batch order. Entries never point
+// outside their manifest's folder, so outputs of re-queued jobs with their own output
+// folder get a manifest there. Matched always covers the whole batch.
+func (e *Encoder) writeManifests() ([]string, error) {
+	jobs := e.GetJobs()
+
+	matched := true
+	var reference *ManifestEntry
+	var dirs []string
+	outputs := make(map[string][]ManifestEntry)
+	for i, job := range jobs {
+		if job.Status != StatusCompleted || job.Settings == nil {
+			continue
+		}
+
+		entry, err := manifestEntry(i+1, job)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filepath.Base(job.OutputPath), err)
+		}
+		if reference == nil {
+			reference = entry
+		} else if entry.Frames != reference.Frames || entry.Width != reference.Width || entry.Height != reference.Height || math.Abs(entry.FPS-reference.FPS) > 0.001 {
+			matched = false
+		}
+		if job.KeyframeCheck != nil && !job.KeyframeCheck.Passed {
+			matched = false
+		}
+
+		dir := filepath.Dir(job.OutputPath)
+		if _, ok := outputs[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		outputs[dir] = append(outputs[dir], *entry)
+	}
+
+	created := time.Now().Format(time.RFC3339)
+	paths := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		data, err := json.MarshalIndent(Manifest{
+			Version: manifestVersion,
+			Created: created,
+			Matched: matched,
+			Outputs: outputs[dir],
+		}, "", "  ")
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(dir, manifestName)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// manifestEntry probes an output for its manifest entry
+func manifestEntry(screen int, job *EncodingJob) (*ManifestEntry, error) {
+	info, err := fileinfo.GetFileInfo(job.OutputPath)
+	if err != nil {
+		return nil, err
+	}
+	packets, err := outputPackets(job)
+	if err != nil {
+		return nil, err
+	}
+	sum := job.SHA256
+	if sum == "" {
+		if sum, err = fileSHA256(job.OutputPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ManifestEntry{
+		Screen:   screen,
+		Label:    job.Options.ScreenLabel,
+		Path:     filepath.Base(job.OutputPath),
+		Source:   job.InputPath,
+		Duration: info.DurationSeconds,
+		Frames:   len(packets),
+		FPS:      info.Framerate,
+		Width:    info.Width,
+		Height:   info.Height,
+		SHA256:   sum,
+		Settings: *job.Settings,
+	}, nil
+}
@@ -0,0 +1,35 @@
+package encoder
+
+import (
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+)
+
+// overlayFontCandidates are monospace fonts tried for the calibration overlay, per OS
+var overlayFontCandidates = map[string][]string{
+	"windows": {`C:\Windows\Fonts\consola.ttf`, `C:\Windows\Fonts\cour.ttf`, `C:\Windows\Fonts\arial.ttf`},
+	"darwin":  {"/System/Library/Fonts/Menlo.ttc", "/System/Library/Fonts/Monaco.ttf", "/System/Library/Fonts/Helvetica.ttc"},
+	"linux": {
+		"/usr/share/fonts/truetype/dejavu/DejaVuSansMono.ttf",
+		"/usr/share/fonts/dejavu-sans-mono-fonts/DejaVuSansMono.ttf",
+		"/usr/share/fonts/TTF/DejaVuSansMono.ttf",
+		"/usr/share/fonts/truetype/liberation/LiberationMono-Regular.ttf",
+	},
+}
+
+// overlayFontFile returns a font file for drawtext ("" = let fontconfig choose)
+func overlayFontFile() string {
+	for _, path := range overlayFontCandidates[goruntime.GOOS] {
+		if _, err := os.Stat(path); err == nil {
+			return filepath.ToSlash(path)
+		}
+	}
+	return ""
+}
+
+// calibrationPath returns the output path of the calibration variant of an output
+func calibrationPath(outputPath string) string {
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + "_calibration.mkv"
+}
@@ -0,0 +1,92 @@
+package encoder
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RenderDevice represents a DRM render node usable for VAAPI/QSV encoding
+type RenderDevice struct {
+	Path   string `json:"path"`   // e.g., "/dev/dri/renderD128"
+	Vendor string `json:"vendor"` // e.g., "Intel", "AMD"
+	Driver string `json:"driver"` // kernel driver, e.g., "i915", "amdgpu"
+}
+
+// encoderDeviceSeparator joins an encoder name and its render device in an encoder ID
+const encoderDeviceSeparator = "@"
+
+// pciVendorNames maps PCI vendor IDs from sysfs to display names
+var pciVendorNames = map[string]string{
+	"0x8086": "Intel",
+	"0x1002": "AMD",
+	"0x10de": "NVIDIA",
+}
+
+// ListRenderDevices enumerates /dev/dri/renderD* nodes with vendor names from sysfs
+func ListRenderDevices() []RenderDevice {
+	nodes, _ := filepath.Glob("/dev/dri/renderD*")
+	sort.Strings(nodes)
+
+	var devices []RenderDevice
+	for _, node := range nodes {
+		sysDir := filepath.Join("/sys/class/drm", filepath.Base(node), "device")
+
+		vendorID, _ := os.ReadFile(filepath.Join(sysDir, "vendor"))
+		vendor := pciVendorNames[strings.TrimSpace(string(vendorID))]
+		if vendor == "" {
+			vendor = strings.TrimSpace(string(vendorID))
+		}
+
+		driver, _ := os.Readlink(filepath.Join(sysDir, "driver"))
+
+		devices = append(devices, RenderDevice{
+			Path:   node,
+			Vendor: vendor,
+			Driver: filepath.Base(driver),
+		})
+	}
+
+	return devices
+}
+
+// splitEncoderID splits an encoder ID like "hevc_vaapi@/dev/dri/renderD129"
+// into the FFmpeg encoder name and render device ("" = default device)
+func splitEncoderID(id string) (encoderID string, device string) {
+	encoderID, device, _ = strings.Cut(id, encoderDeviceSeparator)
+	return encoderID, device
+}
+
+// expandRenderDevices returns one candidate per render device for encoders that
+// need a DRM device, so machines with an iGPU plus a dGPU can choose either.
+// Every candidate names its device in the ID, so FFmpeg is always given the device
+// explicitly instead of falling back to renderD128.
+func expandRenderDevices(encoders []HWEncoder, devices []RenderDevice) []HWEncoder {
+	if len(devices) == 0 {
+		return encoders
+	}
+
+	var result []HWEncoder
+	for _, enc := range encoders {
+		if enc.ID != "hevc_vaapi" && enc.ID != "hevc_qsv" {
+			result = append(result, enc)
+			continue
+		}
+
+		for i, dev := range devices {
+			// QuickSync only exists on Intel GPUs
+			if enc.ID == "hevc_qsv" && dev.Vendor != "Intel" {
+				continue
+			}
+			candidate := enc
+			candidate.ID = enc.ID + encoderDeviceSeparator + dev.Path
+			candidate.Device = dev.Path
+			candidate.Name = enc.Name + " (" + dev.Vendor + ", " + filepath.Base(dev.Path) + ")"
+			candidate.Priority = enc.Priority - i
+			result = append(result, candidate)
+		}
+	}
+
+	return result
+}
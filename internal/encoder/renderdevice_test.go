@@ -0,0 +1,52 @@
+package encoder
+
+import (
+	"testing"
+
+	"syncLauperVideoConverter/internal/preset"
+)
+
+func TestExpandRenderDevices(t *testing.T) {
+	encoders := []HWEncoder{
+		{ID: "hevc_nvenc", Name: "NVENC", Priority: 100},
+		{ID: "hevc_qsv", Name: "QSV", Priority: 90},
+		{ID: "hevc_vaapi", Name: "VAAPI", Priority: 80},
+	}
+	devices := []RenderDevice{
+		{Path: "/dev/dri/renderD129", Vendor: "AMD"},
+		{Path: "/dev/dri/renderD130", Vendor: "Intel"},
+	}
+
+	got := expandRenderDevices(encoders, devices)
+	wantIDs := []string{
+		"hevc_nvenc",
+		"hevc_qsv@/dev/dri/renderD130",
+		"hevc_vaapi@/dev/dri/renderD129",
+		"hevc_vaapi@/dev/dri/renderD130",
+	}
+	if len(got) != len(wantIDs) {
+		t.Fatalf("got %d encoders, want %d", len(got), len(wantIDs))
+	}
+	for i, id := range wantIDs {
+		if got[i].ID != id {
+			t.Errorf("encoder %d = %s, want %s", i, got[i].ID, id)
+		}
+	}
+
+	// The first device is passed to FFmpeg explicitly, not as the renderD128 default
+	encoderID, device := splitEncoderID(got[2].ID)
+	args := preset.GetPreInputArgs(encoderID, device)
+	if len(args) < 2 || args[1] != "vaapi=hw:/dev/dri/renderD129" {
+		t.Errorf("pre-input args = %v, want the renderD129 device", args)
+	}
+
+	// A single device is named explicitly too
+	single := expandRenderDevices(encoders[2:], devices[:1])
+	if len(single) != 1 || single[0].ID != "hevc_vaapi@/dev/dri/renderD129" {
+		t.Errorf("single device = %+v", single)
+	}
+
+	if none := expandRenderDevices(encoders, nil); len(none) != len(encoders) || none[2].ID != "hevc_vaapi" {
+		t.Errorf("no devices = %+v, want the encoders unchanged", none)
+	}
+}
@@ -0,0 +1,268 @@
+package encoder
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"syncLauperVideoConverter/internal/fileinfo"
+)
+
+// Report export formats
+const (
+	ReportJSON = "json"
+	ReportCSV  = "csv"
+	ReportHTML = "html"
+)
+
+// JobReport represents one job of a batch report
+type JobReport struct {
+	Source      string  `json:"source"`
+	Output      string  `json:"output"`
+	Status      string  `json:"status"`
+	Error       string  `json:"error,omitempty"`
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	FPS         float64 `json:"fps"`
+	Codec       string  `json:"codec"`
+	Duration    float64 `json:"duration"` // source seconds
+	SourceSize  int64   `json:"sourceSize"`
+	Preset      string  `json:"preset"`
+	Encoder     string  `json:"encoder"`
+	Quality     int     `json:"quality"`    // CRF (0 = encoder default)
+	OutputSize  int64   `json:"outputSize"` // bytes (0 = no output)
+	Compression float64 `json:"compression"`
+	EncodeTime  float64 `json:"encodeTime"` // seconds
+	Speed       float64 `json:"speed"`      // media seconds per second
+	SHA256      string  `json:"sha256,omitempty"`
+
+	BitrateCheck  *BitrateCheck   `json:"bitrateCheck,omitempty"`
+	FrameCheck    *FrameCheck     `json:"frameCheck,omitempty"`
+	KeyframeCheck *KeyframeCheck  `json:"keyframeCheck,omitempty"`
+	LoopCheck     *LoopCheck      `json:"loopCheck,omitempty"`
+	Loudness      *LoudnessReport `json:"loudness,omitempty"`
+}
+
+// BatchReport represents the results of a batch
+type BatchReport struct {
+	Started       time.Time   `json:"started"`
+	Finished      time.Time   `json:"finished"`
+	Completed     int         `json:"completed"`
+	Failed        int         `json:"failed"`
+	ManifestPaths []string    `json:"manifestPaths,omitempty"` // one per output folder
+	Jobs          []JobReport `json:"jobs"`
+}
+
+// buildReport collects the report of the current jobs
+func (e *Encoder) buildReport(started time.Time, manifestPaths []string) *BatchReport {
+	report := &BatchReport{
+		Started:       started,
+		Finished:      time.Now(),
+		ManifestPaths: manifestPaths,
+		Jobs:          []JobReport{},
+	}
+
+	for _, job := range e.GetJobs() {
+		switch job.Status {
+		case StatusCompleted:
+			report.Completed++
+		case StatusError:
+			report.Failed++
+		}
+
+		settings := e.JobSettings(job.Options)
+		r := JobReport{
+			Source:        job.InputPath,
+			Output:        job.OutputPath,
+			Status:        job.Status,
+			Error:         job.Error,
+			Width:         job.FileInfo.Width,
+			Height:        job.FileInfo.Height,
+			FPS:           job.FileInfo.Framerate,
+			Codec:         job.FileInfo.Codec,
+			Duration:      job.FileInfo.DurationSeconds,
+			SourceSize:    job.FileInfo.FileSize,
+			Preset:        job.Preset.Name,
+			Encoder:       settings.Encoder,
+			Quality:       settings.Quality,
+			OutputSize:    job.OutputSize,
+			EncodeTime:    job.EncodeSeconds,
+			SHA256:        job.SHA256,
+			BitrateCheck:  job.BitrateCheck,
+			FrameCheck:    job.FrameCheck,
+			KeyframeCheck: job.KeyframeCheck,
+			LoopCheck:     job.LoopCheck,
+			Loudness:      job.Loudness,
+		}
+		if job.Settings != nil {
+			r.Encoder = job.Settings.Encoder
+			r.Quality = job.Settings.Quality
+		}
+		if r.OutputSize > 0 {
+			r.Compression = float64(r.SourceSize) / float64(r.OutputSize)
+		}
+		if r.EncodeTime > 0 {
+			r.Speed = job.Options.TrimmedDuration(r.Duration) / r.EncodeTime
+		}
+		report.Jobs = append(report.Jobs, r)
+	}
+
+	return report
+}
+
+// ExportReport writes a batch report as JSON, CSV or a standalone HTML page
+func ExportReport(report *BatchReport, path string, format string) error {
+	if report == nil {
+		return fmt.Errorf("no report available")
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch format {
+	case ReportJSON:
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	case ReportCSV:
+		err = writeReportCSV(file, report)
+	case ReportHTML:
+		err = reportTemplate.Execute(file, report)
+	default:
+		err = fmt.Errorf("unknown report format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// reportColumns are the CSV header of a batch report
+var reportColumns = []string{
+	"source", "output", "status", "error", "width", "height", "fps", "codec", "duration",
+	"source_size", "preset", "encoder", "quality", "output_size", "compression", "encode_time",
+	"speed", "sha256", "bitrate_check", "frame_check", "keyframe_check", "loop_check", "loudness_lufs",
+}
+
+// writeReportCSV writes one row per job
+func writeReportCSV(file *os.File, report *BatchReport) error {
+	w := csv.NewWriter(file)
+	if err := w.Write(reportColumns); err != nil {
+		return err
+	}
+
+	for _, r := range report.Jobs {
+		loudness := ""
+		if r.Loudness != nil && r.Loudness.Output != nil {
+			loudness = formatFloat(r.Loudness.Output.I, 1)
+		}
+		row := []string{
+			r.Source, r.Output, r.Status, r.Error,
+			strconv.Itoa(r.Width), strconv.Itoa(r.Height), formatFloat(r.FPS, 3), r.Codec, formatFloat(r.Duration, 3),
+			strconv.FormatInt(r.SourceSize, 10), r.Preset, r.Encoder, strconv.Itoa(r.Quality),
+			strconv.FormatInt(r.OutputSize, 10), formatFloat(r.Compression, 2), formatFloat(r.EncodeTime, 1),
+			formatFloat(r.Speed, 2), r.SHA256,
+		}
+		row = append(row, r.Checks()...)
+		row = append(row, loudness)
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// Checks returns the bitrate, frame count, keyframe and loop verification results
+func (r JobReport) Checks() []string {
+	return []string{
+		checkResult(r.BitrateCheck != nil, r.BitrateCheck != nil && r.BitrateCheck.Passed),
+		checkResult(r.FrameCheck != nil, r.FrameCheck != nil && r.FrameCheck.Passed),
+		checkResult(r.KeyframeCheck != nil, r.KeyframeCheck != nil && r.KeyframeCheck.Passed),
+		checkResult(r.LoopCheck != nil, r.LoopCheck != nil && r.LoopCheck.Passed),
+	}
+}
+
+// checkResult formats a verification result for the report ("" = not checked)
+func checkResult(checked, passed bool) string {
+	switch {
+	case !checked:
+		return ""
+	case passed:
+		return "passed"
+	default:
+		return "failed"
+	}
+}
+
+func formatFloat(v float64, precision int) string {
+	return strconv.FormatFloat(v, 'f', precision, 64)
+}
+
+// ReportFilename returns the default export filename of a report
+func ReportFilename(report *BatchReport, format string) string {
+	return fmt.Sprintf("synclauper_report_%s.%s", report.Finished.Format("20060102-150405"), format)
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"base":   filepath.Base,
+	"size":   fileinfo.FormatFileSize,
+	"float":  formatFloat,
+	"time":   func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	"status": func(s string) string { return strings.ToUpper(s) },
+}).Parse(`<!DOCTYPE html>
+<html lang="ko">
+<head>
+<meta charset="utf-8">
+<title>SyncLauper 인코딩 보고서 {{time .Finished}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #1b2636; }
+table { border-collapse: collapse; width: 100%; font-size: 13px; }
+th, td { border: 1px solid #ccd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #eef; }
+.completed { color: #1a7f37; }
+.error, .cancelled, .failed { color: #cf222e; }
+.passed { color: #1a7f37; }
+code { font-size: 11px; }
+</style>
+</head>
+<body>
+<h1>SyncLauper 인코딩 보고서</h1>
+<p>{{time .Started}} – {{time .Finished}} · 완료 {{.Completed}} · 실패 {{.Failed}}{{if .ManifestPaths}} · 매니페스트{{range .ManifestPaths}} <code>{{.}}</code>{{end}}{{end}}</p>
+<table>
+<tr>
+<th>소스</th><th>상태</th><th>소스 정보</th><th>프리셋</th><th>인코더</th><th>품질</th>
+<th>출력 크기</th><th>압축률</th><th>인코딩 시간</th><th>속도</th>
+<th>비트레이트</th><th>프레임</th><th>키프레임</th><th>루프</th><th>라우드니스</th><th>SHA-256</th>
+</tr>
+{{range .Jobs}}
+<tr>
+<td>{{base .Source}}<br><code>{{.Output}}</code>{{if .Error}}<br><span class="error">{{.Error}}</span>{{end}}</td>
+<td class="{{.Status}}">{{status .Status}}</td>
+<td>{{.Width}}×{{.Height}} {{float .FPS 3}}fps {{.Codec}}<br>{{float .Duration 2}}s · {{size .SourceSize}}</td>
+<td>{{.Preset}}</td>
+<td>{{.Encoder}}</td>
+<td>{{if .Quality}}CRF {{.Quality}}{{else}}기본{{end}}</td>
+<td>{{if .OutputSize}}{{size .OutputSize}}{{end}}</td>
+<td>{{if .Compression}}{{float .Compression 2}}:1{{end}}</td>
+<td>{{if .EncodeTime}}{{float .EncodeTime 1}}s{{end}}</td>
+<td>{{if .Speed}}{{float .Speed 2}}x{{end}}</td>
+{{range .Checks}}<td class="{{.}}">{{.}}</td>{{end}}
+<td>{{if .Loudness}}{{if .Loudness.Output}}{{float .Loudness.Output.I 1}} LUFS{{end}}{{end}}</td>
+<td><code>{{.SHA256}}</code></td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,49 @@
+package encoder
+
+import (
+	"fmt"
+	"os"
+
+	"syncLauperVideoConverter/internal/fileinfo"
+	"syncLauperVideoConverter/internal/preset"
+)
+
+// prepareRoll checks the file of an image or clip roll and probes clips so their
+// length and audio are known when the filter graph is built
+func prepareRoll(r preset.Roll) (preset.Roll, error) {
+	if r.Kind != preset.RollImage && r.Kind != preset.RollClip {
+		return r, nil
+	}
+	if r.Path == "" {
+		return r, fmt.Errorf("삽입할 파일이 지정되지 않았습니다")
+	}
+	if _, err := os.Stat(r.Path); err != nil {
+		return r, fmt.Errorf("삽입할 파일을 찾을 수 없습니다: %v", err)
+	}
+	if r.Kind == preset.RollImage {
+		return r, nil
+	}
+
+	info, err := fileinfo.GetFileInfo(r.Path)
+	if err != nil {
+		return r, fmt.Errorf("클립을 분석할 수 없습니다: %v", err)
+	}
+	if info.DurationSeconds <= 0 {
+		return r, fmt.Errorf("클립 길이를 확인할 수 없습니다: %s", info.Name)
+	}
+	r.ClipDuration = info.DurationSeconds
+	r.ClipHasAudio = len(info.AudioTracks) > 0
+	return r, nil
+}
+
+// rollFrameTolerance returns the frame count tolerance of the rolls: clip frame
+// counts are estimated from their duration, so each clip may be off by one frame
+func rollFrameTolerance(rolls ...preset.Roll) int {
+	tolerance := 0
+	for _, r := range rolls {
+		if r.Kind == preset.RollClip {
+			tolerance++
+		}
+	}
+	return tolerance
+}
@@ -0,0 +1,83 @@
+package encoder
+
+import (
+	"fmt"
+
+	"syncLauperVideoConverter/internal/preset"
+)
+
+// Settings represents the encoder-wide settings applied to the jobs of a batch.
+// A re-queued job carries its own copy in JobOptions.
+type Settings struct {
+	Encoder        string               `json:"encoder"` // "" = libx265
+	Quality        int                  `json:"quality"`
+	BitDepth       int                  `json:"bitDepth"`
+	HDRMode        string               `json:"hdrMode"`
+	ToneMap        string               `json:"toneMap"`
+	Audio          *preset.AudioOptions `json:"audio,omitempty"` // nil = use preset
+	LoudnessTarget float64              `json:"loudnessTarget"`
+	HWDecode       bool                 `json:"hwDecode"`
+	PreRoll        preset.Roll          `json:"preRoll"`
+	PostRoll       preset.Roll          `json:"postRoll"`
+	Fades          preset.FadeOptions   `json:"fades"`
+	Calibration    bool                 `json:"calibration"`
+	Loop           LoopOptions          `json:"loop"`
+}
+
+// GetSettings returns a snapshot of the encoder-wide settings
+func (e *Encoder) GetSettings() Settings {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	s := Settings{
+		Encoder:        e.selectedEncoder,
+		Quality:        e.qualityLevel,
+		BitDepth:       e.bitDepth,
+		HDRMode:        e.hdrMode,
+		ToneMap:        e.toneMap,
+		LoudnessTarget: e.loudnessTarget,
+		HWDecode:       e.hwDecode,
+		PreRoll:        e.preRoll,
+		PostRoll:       e.postRoll,
+		Fades:          e.fades,
+		Calibration:    e.calibration,
+		Loop:           e.loopOptions,
+	}
+	if s.Encoder == "" {
+		s.Encoder = "libx265" // Default to software
+	}
+	if e.audioOptions != nil {
+		audio := *e.audioOptions
+		s.Audio = &audio
+	}
+	return s
+}
+
+// JobSettings returns the settings a job runs with: its own when it was re-queued
+// with them, the current encoder-wide settings otherwise
+func (e *Encoder) JobSettings(opts JobOptions) Settings {
+	if opts.Settings != nil {
+		return *opts.Settings
+	}
+	return e.GetSettings()
+}
+
+// PrepareSettings validates settings taken with GetSettings for use as per-job
+// settings: the encoder must still be available and roll files must still exist
+func (e *Encoder) PrepareSettings(s Settings) (Settings, error) {
+	if s.Encoder == "" {
+		s.Encoder = "libx265"
+	}
+	if e.availableEncoder(s.Encoder) == nil {
+		return s, fmt.Errorf("인코더를 사용할 수 없습니다: %s", s.Encoder)
+	}
+
+	var err error
+	if s.PreRoll, err = prepareRoll(s.PreRoll); err != nil {
+		return s, err
+	}
+	if s.PostRoll, err = prepareRoll(s.PostRoll); err != nil {
+		return s, err
+	}
+	return s, nil
+}
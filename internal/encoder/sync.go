@@ -0,0 +1,221 @@
+package encoder
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"os/exec"
+	"strconv"
+
+	"syncLauperVideoConverter/internal/cmdutil"
+	"syncLauperVideoConverter/internal/fileinfo"
+)
+
+// Audio sync analysis settings
+const (
+	syncSampleRate      = 2000 // Hz; 0.5 ms resolution is well below one frame
+	syncAnalyzeSeconds  = 180  // analyze the first minutes of each file
+	syncMinOverlapRatio = 0.25 // ignore lags where the files overlap less than this
+)
+
+// SyncOffset is the start offset of a file against the reference, found by
+// cross-correlating their audio
+type SyncOffset struct {
+	Path         string  `json:"path"`
+	OffsetMs     int     `json:"offsetMs"`        // + = the same moment happens later in this file than in the reference
+	OffsetFrames int     `json:"offsetFrames"`    // offset at the file's framerate
+	Confidence   float64 `json:"confidence"`      // normalized correlation peak (0..1, low = unreliable)
+	Error        string  `json:"error,omitempty"` // analysis error for this file ("" = ok)
+}
+
+// AnalyzeSyncOffsets measures the offset of each file against the reference file
+func (f *FFmpeg) AnalyzeSyncOffsets(ctx context.Context, reference string, files []*fileinfo.FileInfo) ([]SyncOffset, error) {
+	ref, err := f.extractSyncAudio(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make([]SyncOffset, 0, len(files))
+	for _, file := range files {
+		offset := SyncOffset{Path: file.Path}
+		if file.Path == reference {
+			offset.Confidence = 1
+			offsets = append(offsets, offset)
+			continue
+		}
+
+		samples, err := f.extractSyncAudio(ctx, file.Path)
+		if err != nil {
+			if ctx.Err() != nil {
+				return offsets, ctx.Err()
+			}
+			offset.Error = err.Error()
+			offsets = append(offsets, offset)
+			continue
+		}
+
+		lag, confidence := crossCorrelate(ref, samples)
+		seconds := float64(lag) / syncSampleRate
+		offset.OffsetMs = int(math.Round(seconds * 1000))
+		offset.OffsetFrames = int(math.Round(seconds * file.Framerate))
+		offset.Confidence = confidence
+		offsets = append(offsets, offset)
+	}
+	return offsets, nil
+}
+
+// SyncTrims converts offsets into start trims in seconds, so every file starts on the
+// moment of the latest-starting file (files with errors are left untrimmed)
+func SyncTrims(offsets []SyncOffset) map[string]float64 {
+	minMs := 0
+	for _, o := range offsets {
+		if o.Error == "" && o.OffsetMs < minMs {
+			minMs = o.OffsetMs
+		}
+	}
+
+	trims := make(map[string]float64, len(offsets))
+	for _, o := range offsets {
+		if o.Error != "" {
+			continue
+		}
+		trims[o.Path] = float64(o.OffsetMs-minMs) / 1000
+	}
+	return trims
+}
+
+// extractSyncAudio decodes the first audio track as low-rate mono float samples
+func (f *FFmpeg) extractSyncAudio(ctx context.Context, path string) ([]float64, error) {
+	args := []string{
+		"-hide_banner",
+		"-v", "error",
+		"-i", path,
+		"-map", "0:a:0",
+		"-t", strconv.Itoa(syncAnalyzeSeconds),
+		"-ac", "1",
+		"-ar", strconv.Itoa(syncSampleRate),
+		"-f", "f32le", "pipe:1",
+	}
+
+	cmd := exec.CommandContext(ctx, f.config.ExecutablePath, args...)
+	cmdutil.HideWindow(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("오디오 추출 실패: %v", err)
+	}
+	if len(output) < 4*syncSampleRate {
+		return nil, fmt.Errorf("분석할 오디오가 없습니다")
+	}
+
+	// First difference removes DC and low-frequency rumble that would blur the peak
+	samples := make([]float64, len(output)/4)
+	prev := 0.0
+	for i := range samples {
+		v := float64(math.Float32frombits(binary.LittleEndian.Uint32(output[i*4:])))
+		samples[i] = v - prev
+		prev = v
+	}
+	return samples, nil
+}
+
+// crossCorrelate finds the lag (in samples) at which b best matches a:
+// b[i+lag] ≈ a[i]. The confidence is the peak normalized by the signal energies.
+func crossCorrelate(a, b []float64) (lag int, confidence float64) {
+	n := 1
+	for n < len(a)+len(b) {
+		n <<= 1
+	}
+
+	fa := make([]complex128, n)
+	fb := make([]complex128, n)
+	for i, v := range a {
+		fa[i] = complex(v, 0)
+	}
+	for i, v := range b {
+		fb[i] = complex(v, 0)
+	}
+	fft(fa, false)
+	fft(fb, false)
+	for i := range fa {
+		fa[i] = cmplx.Conj(fa[i]) * fb[i]
+	}
+	fft(fa, true)
+
+	// Index k holds lag k, index n-k holds lag -k
+	minOverlap := int(float64(min(len(a), len(b))) * syncMinOverlapRatio)
+	best := math.Inf(-1)
+	for k := 0; k < n; k++ {
+		l := k
+		if k >= n/2 {
+			l = k - n
+		}
+		if l >= len(b) || -l >= len(a) {
+			continue
+		}
+		overlap := min(len(a), len(b)-l)
+		if l < 0 {
+			overlap = min(len(a)+l, len(b))
+		}
+		if overlap < minOverlap {
+			continue
+		}
+		if v := real(fa[k]); v > best {
+			best = v
+			lag = l
+		}
+	}
+
+	energyA, energyB := 0.0, 0.0
+	for _, v := range a {
+		energyA += v * v
+	}
+	for _, v := range b {
+		energyB += v * v
+	}
+	if energyA > 0 && energyB > 0 {
+		confidence = math.Max(0, best/math.Sqrt(energyA*energyB))
+	}
+	return lag, confidence
+}
+
+// fft is an in-place iterative radix-2 FFT; len(x) must be a power of two.
+// inverse also scales by 1/n.
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u := x[start+k]
+				v := x[start+k+size/2] * w
+				x[start+k] = u + v
+				x[start+k+size/2] = u - v
+				w *= step
+			}
+		}
+	}
+
+	if inverse {
+		for i := range x {
+			x[i] /= complex(float64(n), 0)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package encoder
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCrossCorrelateShift(t *testing.T) {
+	const length = 8000 // 4 s at syncSampleRate
+	rng := rand.New(rand.NewSource(1))
+	signal := make([]float64, length+2000)
+	for i := range signal {
+		signal[i] = rng.NormFloat64()
+	}
+
+	tests := []struct {
+		name  string
+		shift int // the file starts this many samples earlier in the shared signal than the reference
+		trim  map[string]float64
+	}{
+		{"file starts later", 200, map[string]float64{"ref": 0, "file": 0.1}},
+		{"file starts earlier", -600, map[string]float64{"ref": 0.3, "file": 0}},
+		{"no offset", 0, map[string]float64{"ref": 0, "file": 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// reference[i] == file[i+shift]
+			refStart, fileStart := 1000, 1000-tt.shift
+			ref := signal[refStart : refStart+length]
+			file := signal[fileStart : fileStart+length]
+
+			lag, confidence := crossCorrelate(ref, file)
+			if lag != tt.shift {
+				t.Fatalf("lag = %d, want %d", lag, tt.shift)
+			}
+			wantConfidence := float64(length-abs(tt.shift)) / length
+			if math.Abs(confidence-wantConfidence) > 0.05 {
+				t.Errorf("confidence = %.3f, want about %.3f", confidence, wantConfidence)
+			}
+
+			offsetMs := int(math.Round(float64(lag) / syncSampleRate * 1000))
+			trims := SyncTrims([]SyncOffset{
+				{Path: "ref", Confidence: 1},
+				{Path: "file", OffsetMs: offsetMs},
+			})
+			for path, want := range tt.trim {
+				if math.Abs(trims[path]-want) > 1e-9 {
+					t.Errorf("trim %s = %.3f, want %.3f", path, trims[path], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncTrimsSkipsErrors(t *testing.T) {
+	trims := SyncTrims([]SyncOffset{
+		{Path: "ref"},
+		{Path: "late", OffsetMs: 40},
+		{Path: "broken", OffsetMs: -500, Error: "no audio"},
+	})
+	if _, ok := trims["broken"]; ok {
+		t.Errorf("file with an error was trimmed")
+	}
+	if trims["ref"] != 0 || trims["late"] != 0.04 {
+		t.Errorf("trims = %v, want ref 0 and late 0.04", trims)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
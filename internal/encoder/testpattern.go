@@ -0,0 +1,153 @@
+package encoder
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"syncLauperVideoConverter/internal/preset"
+)
+
+// TestPatternOptions configures the multi-screen sync test pattern
+type TestPatternOptions struct {
+	Screens       int     `json:"screens"`       // number of screens in the rig
+	Duration      float64 `json:"duration"`      // seconds (0 = 60)
+	FlashInterval float64 `json:"flashInterval"` // seconds between white flashes and beeps (0 = 2)
+}
+
+// Test pattern defaults used when the preset keeps source values
+const (
+	testPatternWidth    = 1920
+	testPatternHeight   = 1080
+	testPatternFPS      = 30.0
+	testPatternBeepSecs = 0.05
+	testPatternBeepFreq = 1000
+)
+
+// GenerateTestPattern encodes one synchronized test file per screen with the selected
+// encoder: frame counter, a sweep bar that travels across all screens side by side,
+// and a white flash with a matching beep every flash interval. Everything is generated
+// by lavfi sources, so no input file is needed.
+func (e *Encoder) GenerateTestPattern(p *preset.Preset, outputDir string, opts TestPatternOptions) ([]string, error) {
+	if opts.Screens <= 0 {
+		return nil, fmt.Errorf("screen count must be at least 1")
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = 60
+	}
+	if opts.FlashInterval <= 0 {
+		opts.FlashInterval = 2
+	}
+
+	e.mu.Lock()
+	if e.isRunning {
+		e.mu.Unlock()
+		return nil, fmt.Errorf("encoding already in progress")
+	}
+	e.isRunning = true
+	e.cancelCtx, e.cancelFunc = context.WithCancel(context.Background())
+	ctx := e.cancelCtx
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		e.isRunning = false
+		e.mu.Unlock()
+	}()
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+
+	// Generated sources are progressive stereo at the preset size and rate
+	width, height, fps, _ := p.EffectiveValues(nil)
+	if p.UseSourceRes || width <= 0 || height <= 0 {
+		width, height = testPatternWidth, testPatternHeight
+	}
+	if p.UseSourceFPS || fps <= 0 {
+		fps = testPatternFPS
+	}
+	sourceInfo := &preset.FileInfo{
+		Width:       width,
+		Height:      height,
+		Framerate:   fps,
+		Duration:    opts.Duration,
+		AudioTracks: []preset.AudioTrack{{Codec: "pcm_f32le", Channels: 2}},
+	}
+
+	encoderID, renderDevice := splitEncoderID(e.GetSelectedEncoder())
+	encodeOpts := preset.EncodeOptions{
+		EncoderID:    encoderID,
+		RenderDevice: renderDevice,
+		Quality:      e.GetQuality(),
+		BitDepth:     e.GetBitDepth(),
+		InputFormat:  "lavfi",
+	}
+
+	outputs := make([]string, 0, opts.Screens)
+	for screen := 0; screen < opts.Screens; screen++ {
+		name := fmt.Sprintf("sync_test_screen%dof%d.mkv", screen+1, opts.Screens)
+		outputPath := filepath.Join(outputDir, name)
+		graph := testPatternGraph(screen, opts.Screens, width, height, fps, overlayFontFile(), opts)
+		args := p.ToFFmpegArgsWithOptions(graph, outputPath, sourceInfo, encodeOpts)
+
+		progressWrapper := func(progress *EncodingProgress) {
+			progress.Filename = name
+			progress.CurrentFile = screen + 1
+			progress.TotalFiles = opts.Screens
+			if e.progressCb != nil {
+				e.progressCb(progress)
+			}
+		}
+
+		result, err := e.ffmpeg.Encode(ctx, args, opts.Duration, progressWrapper)
+		if err != nil {
+			return outputs, err
+		}
+		if !result.Success {
+			return outputs, fmt.Errorf("%s: %s", name, result.Error)
+		}
+		outputs = append(outputs, outputPath)
+	}
+	return outputs, nil
+}
+
+// testPatternGraph builds the lavfi graph of one screen. The sweep bar position is
+// computed on the canvas of all screens side by side, then shifted by the screen's
+// offset, so it leaves one screen exactly when it enters the next. The bar is its own
+// color source placed by overlay, whose position is evaluated on every frame
+// (drawbox evaluates its position only once).
+func testPatternGraph(screen, screens, width, height int, fps float64, fontFile string, opts TestPatternOptions) string {
+	font := ""
+	if fontFile != "" {
+		font = "fontfile=" + preset.FilterEscape(fontFile) + ":"
+	}
+	rate := fmt.Sprintf("%.6f", fps)
+	flashFrames := int(math.Max(1, math.Round(opts.FlashInterval*fps)))
+	flashPeriod := float64(flashFrames) / fps
+
+	// The bar crosses one screen width per second
+	barWidth := width / 40
+	canvas := screens * width
+	sweep := fmt.Sprintf("mod(n*%f,%d)-%d", float64(width)/fps, canvas+barWidth, screen*width+barWidth)
+
+	sources := fmt.Sprintf("color=c=0x202020:s=%dx%d:r=%s:d=%.3f[bg];color=c=white:s=%dx%d:r=%s:d=%.3f[bar];",
+		width, height, rate, opts.Duration, barWidth, height, rate, opts.Duration)
+	video := []string{
+		fmt.Sprintf("[bg][bar]overlay=x=%s:y=0:eval=frame", preset.FilterEscape(sweep)),
+		fmt.Sprintf("drawtext=%stext=%s:fontsize=h/5:fontcolor=white:x=(w-tw)/2:y=(h-th)/2",
+			font, preset.FilterEscape("%{n}")),
+		fmt.Sprintf("drawtext=%sexpansion=none:text=%s:fontsize=h/12:fontcolor=white:x=(w-tw)/2:y=h/10",
+			font, preset.FilterEscape(fmt.Sprintf("SCREEN %d/%d", screen+1, screens))),
+		fmt.Sprintf("drawbox=x=0:y=0:w=iw:h=ih:color=white:t=fill:enable=%s",
+			preset.FilterEscape(fmt.Sprintf("eq(mod(n,%d),0)", flashFrames))),
+	}
+
+	// The beep starts with each flash frame
+	beep := fmt.Sprintf("0.5*sin(2*PI*%d*t)*lt(mod(t,%f),%f)", testPatternBeepFreq, flashPeriod, testPatternBeepSecs)
+	audio := fmt.Sprintf("aevalsrc=%s:c=stereo:s=48000:d=%.3f", preset.FilterEscape(beep), opts.Duration)
+
+	return sources + strings.Join(video, ",") + "[out0];" + audio + "[out1]"
+}
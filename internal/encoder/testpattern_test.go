@@ -0,0 +1,29 @@
+package encoder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTestPatternGraph(t *testing.T) {
+	opts := TestPatternOptions{Screens: 2, Duration: 10, FlashInterval: 2}
+
+	// Second of two 1920x1080 screens: the bar is 48 px wide, sweeps 64 px per frame
+	// over the 3840 px canvas plus its own width, and is shifted by one screen
+	expected := "color=c=0x202020:s=1920x1080:r=30.000000:d=10.000[bg];" +
+		"color=c=white:s=48x1080:r=30.000000:d=10.000[bar];" +
+		`[bg][bar]overlay=x=mod(n*64.000000\,3888)-1968:y=0:eval=frame,` +
+		`drawtext=text=%{n}:fontsize=h/5:fontcolor=white:x=(w-tw)/2:y=(h-th)/2,` +
+		`drawtext=expansion=none:text=SCREEN 2/2:fontsize=h/12:fontcolor=white:x=(w-tw)/2:y=h/10,` +
+		`drawbox=x=0:y=0:w=iw:h=ih:color=white:t=fill:enable=eq(mod(n\,60)\,0)[out0];` +
+		`aevalsrc=0.5*sin(2*PI*1000*t)*lt(mod(t\,2.000000)\,0.050000):c=stereo:s=48000:d=10.000[out1]`
+
+	if got := testPatternGraph(1, 2, 1920, 1080, 30, "", opts); got != expected {
+		t.Errorf("graph:\n got %s\nwant %s", got, expected)
+	}
+
+	withFont := testPatternGraph(0, 1, 1920, 1080, 30, "C:/Windows/Fonts/arial.ttf", opts)
+	if want := `drawtext=fontfile=C\\:/Windows/Fonts/arial.ttf:text=`; !strings.Contains(withFont, want) {
+		t.Errorf("font not escaped as %s in %s", want, withFont)
+	}
+}
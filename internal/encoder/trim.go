@@ -0,0 +1,89 @@
+package encoder
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"syncLauperVideoConverter/internal/preset"
+)
+
+// ParsePosition parses an in/out point as a timecode ("HH:MM:SS:FF"), a clock time
+// ("HH:MM:SS.mmm" or "MM:SS"), a frame count ("120f") or seconds ("12.5") into
+// seconds. Timecode frames and frame counts use fps. "" means no point (0).
+func ParsePosition(text string, fps float64) (float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+
+	if frames, ok := strings.CutSuffix(strings.ToLower(text), "f"); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(frames))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("잘못된 프레임 값입니다: %s", text)
+		}
+		if fps <= 0 {
+			return 0, fmt.Errorf("프레임레이트를 알 수 없어 프레임 단위를 사용할 수 없습니다")
+		}
+		return float64(n) / fps, nil
+	}
+
+	parts := strings.Split(text, ":")
+	if len(parts) > 4 {
+		return 0, fmt.Errorf("잘못된 시간 형식입니다: %s", text)
+	}
+
+	// Timecode: the last field counts frames
+	frames := -1
+	if len(parts) == 4 {
+		if fps <= 0 {
+			return 0, fmt.Errorf("프레임레이트를 알 수 없어 타임코드를 사용할 수 없습니다")
+		}
+		n, err := strconv.Atoi(parts[3])
+		if err != nil || n < 0 || float64(n) >= math.Round(fps) {
+			return 0, fmt.Errorf("잘못된 타임코드입니다: %s", text)
+		}
+		frames = n
+		parts = parts[:3]
+	}
+
+	seconds := 0.0
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil || v < 0 || (i > 0 && v >= 60) {
+			return 0, fmt.Errorf("잘못된 시간 형식입니다: %s", text)
+		}
+		seconds = seconds*60 + v
+	}
+	if frames < 0 {
+		return seconds, nil
+	}
+
+	// Non-drop timecode counts whole frames per nominal second (30 for 29.97)
+	nominal := math.Round(fps)
+	return (seconds*nominal + float64(frames)) / fps, nil
+}
+
+// trimStart returns the source position the output starts at
+func (o JobOptions) trimStart() float64 {
+	return o.InPoint + o.TrimStart
+}
+
+// trimEnd returns the source position the output ends at (0 = the end)
+func (o JobOptions) trimEnd() float64 {
+	if o.OutPoint <= 0 {
+		return 0
+	}
+	return o.OutPoint + o.TrimStart
+}
+
+// Trimmed reports whether the options cut the source
+func (o JobOptions) Trimmed() bool {
+	return o.trimStart() > 0 || o.trimEnd() > 0
+}
+
+// TrimmedDuration returns the length of the output's source part for a source duration
+func (o JobOptions) TrimmedDuration(duration float64) float64 {
+	return preset.TrimmedDuration(duration, o.trimStart(), o.trimEnd())
+}
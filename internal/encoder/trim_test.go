@@ -0,0 +1,73 @@
+package encoder
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParsePosition(t *testing.T) {
+	tests := []struct {
+		text    string
+		fps     float64
+		want    float64
+		wantErr bool
+	}{
+		{"", 30, 0, false},
+		{"12.5", 30, 12.5, false},
+		{"01:30", 30, 90, false},
+		{"00:01:02.250", 30, 62.25, false},
+		{"00:00:10:15", 30, 10.5, false},
+		{"00:00:01:00", 29.97, 30 / 29.97, false},
+		{"00:01:00:12", 23.976, (60*24 + 12) / 23.976, false},
+		{"120f", 60, 2, false},
+		{"48F", 24, 2, false},
+		{"00:00:10:30", 30, 0, true}, // frame field beyond the framerate
+		{"00:00:10:05", 0, 0, true},  // timecode needs a framerate
+		{"10f", 0, 0, true},
+		{"00:61", 30, 0, true},
+		{"-5", 30, 0, true},
+		{"abc", 30, 0, true},
+		{"1:2:3:4:5", 30, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParsePosition(tt.text, tt.fps)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePosition(%q, %g) error = %v, wantErr %v", tt.text, tt.fps, err, tt.wantErr)
+			}
+			if !tt.wantErr && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ParsePosition(%q, %g) = %g, want %g", tt.text, tt.fps, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobOptionsTrim(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     JobOptions
+		start    float64
+		end      float64
+		duration float64 // of a 60 s source
+	}{
+		{"untrimmed", JobOptions{}, 0, 0, 60},
+		{"in and out points", JobOptions{InPoint: 5, OutPoint: 25}, 5, 25, 20},
+		{"sync trim shifts both points", JobOptions{InPoint: 5, OutPoint: 25, TrimStart: 0.5}, 5.5, 25.5, 20},
+		{"sync trim only", JobOptions{TrimStart: 1.25}, 1.25, 0, 58.75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.trimStart(); got != tt.start {
+				t.Errorf("trimStart = %g, want %g", got, tt.start)
+			}
+			if got := tt.opts.trimEnd(); got != tt.end {
+				t.Errorf("trimEnd = %g, want %g", got, tt.end)
+			}
+			if got := tt.opts.TrimmedDuration(60); math.Abs(got-tt.duration) > 1e-9 {
+				t.Errorf("TrimmedDuration(60) = %g, want %g", got, tt.duration)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package encoder
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"syncLauperVideoConverter/internal/fileinfo"
+)
+
+// bitrateTolerance allows peaks slightly above the cap for VBV buffer headroom
+const bitrateTolerance = 1.1
+
+// BitrateCheck represents the result of the post-encode peak bitrate verification
+type BitrateCheck struct {
+	PeakKbps   int     `json:"peakKbps"`   // highest bitrate measured over the window
+	PeakAt     float64 `json:"peakAt"`     // start of the peak window in seconds
+	MaxKbps    int     `json:"maxKbps"`    // VBV max bitrate the output was encoded with
+	WindowSecs float64 `json:"windowSecs"` // sliding window length in seconds
+	Passed     bool    `json:"passed"`
+}
+
+// VerifyPeakBitrate measures the peak video bitrate of an output's packets over a
+// sliding window and compares it with the VBV max bitrate
+func VerifyPeakBitrate(packets []fileinfo.Packet, maxKbps int, windowSecs float64) (*BitrateCheck, error) {
+	if windowSecs <= 0 {
+		windowSecs = 1.0
+	}
+
+	if len(packets) == 0 {
+		return nil, fmt.Errorf("no video packets found")
+	}
+
+	// Two-pointer sliding window over packets in decode order
+	var peakBits, windowBits int64
+	var peakAt float64
+	start := 0
+	for end, p := range packets {
+		windowBits += int64(p.Size) * 8
+		for packets[end].DTS-packets[start].DTS >= windowSecs {
+			windowBits -= int64(packets[start].Size) * 8
+			start++
+		}
+		if windowBits > peakBits {
+			peakBits = windowBits
+			peakAt = packets[start].DTS
+		}
+	}
+
+	peakKbps := int(float64(peakBits) / windowSecs / 1000)
+	return &BitrateCheck{
+		PeakKbps:   peakKbps,
+		PeakAt:     peakAt,
+		MaxKbps:    maxKbps,
+		WindowSecs: windowSecs,
+		Passed:     maxKbps <= 0 || float64(peakKbps) <= float64(maxKbps)*bitrateTolerance,
+	}, nil
+}
+
+// FrameCheck represents the result of the post-encode frame count verification
+// of the pre-roll and post-roll
+type FrameCheck struct {
+	IntroFrames    int  `json:"introFrames"`
+	OutroFrames    int  `json:"outroFrames"`
+	SourceFrames   int  `json:"sourceFrames"`   // source frames at the output framerate
+	ExpectedFrames int  `json:"expectedFrames"` // intro + source + outro
+	ActualFrames   int  `json:"actualFrames"`
+	Passed         bool `json:"passed"`
+}
+
+// VerifyFrameCount counts the output video packets and checks that the pre-roll and
+// post-roll added exactly introFrames and outroFrames. Sources at the output framerate are
+// counted exactly; resampled sources are estimated from the duration and may
+// differ by one frame, as are trimmed sources. rollTolerance allows for estimated roll frame counts.
+func VerifyFrameCount(packets []fileinfo.Packet, source *fileinfo.FileInfo, opts JobOptions, outputFPS float64, introFrames, outroFrames, rollTolerance int) (*FrameCheck, error) {
+	sourceFrames := int(math.Round(opts.TrimmedDuration(source.DurationSeconds) * outputFPS))
+	tolerance := 1
+	if !opts.Trimmed() && math.Abs(source.Framerate-outputFPS) < 0.01 {
+		sourcePackets, err := fileinfo.ProbePackets(source.Path)
+		if err != nil {
+			return nil, err
+		}
+		sourceFrames = len(sourcePackets)
+		tolerance = 0
+	}
+	tolerance += rollTolerance
+
+	if len(packets) == 0 {
+		return nil, fmt.Errorf("no video packets found")
+	}
+
+	expected := introFrames + sourceFrames + outroFrames
+	diff := len(packets) - expected
+	return &FrameCheck{
+		IntroFrames:    introFrames,
+		OutroFrames:    outroFrames,
+		SourceFrames:   sourceFrames,
+		ExpectedFrames: expected,
+		ActualFrames:   len(packets),
+		Passed:         diff >= -tolerance && diff <= tolerance,
+	}, nil
+}
+
+// Keyframe alignment settings
+const (
+	keyframeTolerance = 0.002 // seconds; Matroska stores millisecond timestamps
+	keyframeReportMax = 20    // deviations listed per file
+)
+
+// KeyframeCheck represents the keyframe alignment of an output against the
+// first output of its rendition group
+type KeyframeCheck struct {
+	Keyframes    int       `json:"keyframes"`
+	Reference    string    `json:"reference"`    // output the keyframes are compared with
+	ExtraCount   int       `json:"extraCount"`   // keyframes the reference doesn't have
+	MissingCount int       `json:"missingCount"` // reference keyframes this output doesn't have
+	Extra        []float64 `json:"extra"`        // first extra keyframe timestamps in seconds
+	Missing      []float64 `json:"missing"`      // first missing keyframe timestamps in seconds
+	Passed       bool      `json:"passed"`
+}
+
+// outputPackets returns the video packets of a job's output, probing it again only
+// when the scan after the encode failed
+func outputPackets(job *EncodingJob) ([]fileinfo.Packet, error) {
+	if job.packets != nil {
+		return job.packets, nil
+	}
+	return fileinfo.ProbePackets(job.OutputPath)
+}
+
+// outputKeyframeTimes returns the keyframe timestamps of a job's output
+func outputKeyframeTimes(job *EncodingJob) ([]float64, error) {
+	packets, err := outputPackets(job)
+	if err != nil {
+		return nil, err
+	}
+	return KeyframeTimes(packets)
+}
+
+// KeyframeTimes returns the sorted presentation timestamps of the keyframes among packets
+func KeyframeTimes(packets []fileinfo.Packet) ([]float64, error) {
+	var times []float64
+	for _, p := range packets {
+		if p.Keyframe {
+			times = append(times, p.PTS)
+		}
+	}
+	if len(times) == 0 {
+		return nil, fmt.Errorf("no keyframes found")
+	}
+	sort.Float64s(times)
+	return times, nil
+}
+
+// CompareKeyframes compares keyframe timestamps with the reference's
+func CompareKeyframes(referencePath string, reference, times []float64) *KeyframeCheck {
+	check := &KeyframeCheck{
+		Keyframes: len(times),
+		Reference: referencePath,
+		Extra:     []float64{},
+		Missing:   []float64{},
+	}
+
+	// Merge the two sorted lists
+	i, j := 0, 0
+	for i < len(reference) || j < len(times) {
+		switch {
+		case i < len(reference) && j < len(times) && math.Abs(reference[i]-times[j]) <= keyframeTolerance:
+			i++
+			j++
+		case j >= len(times) || (i < len(reference) && reference[i] < times[j]):
+			check.MissingCount++
+			if len(check.Missing) < keyframeReportMax {
+				check.Missing = append(check.Missing, reference[i])
+			}
+			i++
+		default:
+			check.ExtraCount++
+			if len(check.Extra) < keyframeReportMax {
+				check.Extra = append(check.Extra, times[j])
+			}
+			j++
+		}
+	}
+
+	check.Passed = check.ExtraCount == 0 && check.MissingCount == 0
+	return check
+}
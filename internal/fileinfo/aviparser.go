@@ -160,6 +160,7 @@ func aviParseStreamList(r io.ReadSeeker, startPos, endPos int64, info *FileInfo)
 
 	var streamType string
 	var codecFourCC string
+	var channels int
 
 	for {
 		pos, _ := r.Seek(0, io.SeekCurrent)
@@ -216,6 +217,13 @@ func aviParseStreamList(r io.ReadSeeker, startPos, endPos int64, info *FileInfo)
 				}
 			}
 		case "strf":
+			if streamType == "auds" && chunkSize >= 4 {
+				// WAVEFORMATEX: wFormatTag(2) + nChannels(2)
+				var wfx [4]byte
+				if _, err := io.ReadFull(r, wfx[:]); err == nil {
+					channels = int(binary.LittleEndian.Uint16(wfx[2:4]))
+				}
+			}
 			if streamType == "vids" && chunkSize >= 40 {
 				// BITMAPINFOHEADER
 				var bih [40]byte
@@ -247,8 +255,8 @@ func aviParseStreamList(r io.ReadSeeker, startPos, endPos int64, info *FileInfo)
 	if streamType == "vids" && info.Codec == "" {
 		info.Codec = aviCodecName(codecFourCC)
 	}
-	if streamType == "auds" && info.AudioCodec == "" {
-		info.AudioCodec = aviAudioCodecName(codecFourCC)
+	if streamType == "auds" {
+		info.addAudioTrack(aviAudioCodecName(codecFourCC), channels, "")
 	}
 }
 
@@ -0,0 +1,174 @@
+package fileinfo
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// hevcConfigBitDepth reads the luma bit depth from an HEVCDecoderConfigurationRecord (hvcC)
+func hevcConfigBitDepth(data []byte) int {
+	// configurationVersion(1) + profile(1) + compat flags(4) + constraint flags(6) + level(1)
+	// + min_spatial_segmentation(2) + parallelismType(1) + chromaFormat(1) = 17 bytes
+	if len(data) < 18 {
+		return 0
+	}
+	return int(data[17]&0x07) + 8
+}
+
+// avcConfigBitDepth reads the luma bit depth from an AVCDecoderConfigurationRecord (avcC).
+// Only High profiles carry the bit depth; everything else is 8-bit.
+func avcConfigBitDepth(data []byte) int {
+	if len(data) < 6 {
+		return 0
+	}
+
+	profile := data[1]
+	if profile != 100 && profile != 110 && profile != 122 && profile != 144 {
+		return 8
+	}
+
+	// Skip SPS and PPS entries to reach the High profile extension
+	pos := 5
+	numSPS := int(data[pos] & 0x1F)
+	pos++
+	for i := 0; i < numSPS; i++ {
+		if pos+2 > len(data) {
+			return 8
+		}
+		pos += 2 + int(binary.BigEndian.Uint16(data[pos:pos+2]))
+	}
+	if pos >= len(data) {
+		return 8
+	}
+	numPPS := int(data[pos])
+	pos++
+	for i := 0; i < numPPS; i++ {
+		if pos+2 > len(data) {
+			return 8
+		}
+		pos += 2 + int(binary.BigEndian.Uint16(data[pos:pos+2]))
+	}
+
+	// chroma_format(1) + bit_depth_luma_minus8(1)
+	if pos+2 > len(data) {
+		return 8
+	}
+	return int(data[pos+1]&0x07) + 8
+}
+
+// MPEG-4 descriptor tags used in esds
+const (
+	esDescrTag            = 0x03
+	decoderConfigDescrTag = 0x04
+	decSpecificInfoTag    = 0x05
+)
+
+// aacChannelConfigs maps the AudioSpecificConfig channelConfiguration to channel counts
+// (0 = defined by a program config element, not supported)
+var aacChannelConfigs = map[int]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 6: 6, 7: 8, 11: 7, 12: 8, 13: 24, 14: 8}
+
+// esdsAudioChannels reads the channel count from the AudioSpecificConfig in an esds
+// box payload (0 = unknown)
+func esdsAudioChannels(data []byte) int {
+	// version(1) + flags(3)
+	pos := 4
+	readDescriptor := func(tag byte) (int, bool) {
+		if pos >= len(data) || data[pos] != tag {
+			return 0, false
+		}
+		pos++
+		// Expandable size: 7 bits per byte, high bit set on all but the last
+		length := 0
+		for i := 0; i < 4 && pos < len(data); i++ {
+			b := data[pos]
+			pos++
+			length = length<<7 | int(b&0x7F)
+			if b&0x80 == 0 {
+				break
+			}
+		}
+		return length, pos+length <= len(data)
+	}
+
+	if _, ok := readDescriptor(esDescrTag); !ok {
+		return 0
+	}
+	// ES_ID(2) + flags(1), then the optional fields the flags announce
+	if pos+3 > len(data) {
+		return 0
+	}
+	flags := data[pos+2]
+	pos += 3
+	if flags&0x80 != 0 { // streamDependenceFlag
+		pos += 2
+	}
+	if flags&0x40 != 0 && pos < len(data) { // URL_Flag
+		pos += 1 + int(data[pos])
+	}
+	if flags&0x20 != 0 { // OCRstreamFlag
+		pos += 2
+	}
+
+	if _, ok := readDescriptor(decoderConfigDescrTag); !ok {
+		return 0
+	}
+	// objectTypeIndication(1) + streamType(1) + bufferSizeDB(3) + maxBitrate(4) + avgBitrate(4)
+	pos += 13
+	length, ok := readDescriptor(decSpecificInfoTag)
+	if !ok || length < 2 {
+		return 0
+	}
+	return aacConfigChannels(data[pos : pos+length])
+}
+
+// aacConfigChannels reads the channelConfiguration of an AudioSpecificConfig
+func aacConfigChannels(config []byte) int {
+	bits := bitReader{data: config}
+	if objectType := bits.read(5); objectType == 31 {
+		bits.read(6) // audioObjectTypeExt
+	}
+	if frequencyIndex := bits.read(4); frequencyIndex == 0x0F {
+		bits.read(24) // explicit samplingFrequency
+	}
+	channelConfig := bits.read(4)
+	if bits.overrun {
+		return 0
+	}
+	return aacChannelConfigs[channelConfig]
+}
+
+// bitReader reads big-endian bit fields
+type bitReader struct {
+	data    []byte
+	pos     int // bit position
+	overrun bool
+}
+
+// read returns the next n bits (n <= 32)
+func (b *bitReader) read(n int) int {
+	value := 0
+	for i := 0; i < n; i++ {
+		if b.pos >= len(b.data)*8 {
+			b.overrun = true
+			return 0
+		}
+		bit := b.data[b.pos/8] >> (7 - b.pos%8) & 1
+		value = value<<1 | int(bit)
+		b.pos++
+	}
+	return value
+}
+
+// pixFmtBitDepth derives the bit depth from an ffmpeg pixel format name (e.g., "yuv420p10le")
+func pixFmtBitDepth(pixFmt string) int {
+	switch {
+	case pixFmt == "":
+		return 0
+	case strings.Contains(pixFmt, "p10"), strings.Contains(pixFmt, "p010"):
+		return 10
+	case strings.Contains(pixFmt, "p12"), strings.Contains(pixFmt, "p012"):
+		return 12
+	default:
+		return 8
+	}
+}
@@ -0,0 +1,89 @@
+package fileinfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// testBox builds an ISO BMFF box with the given type and payload
+func testBox(boxType string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	box := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint32(box, uint32(8+len(data)))
+	copy(box[4:], boxType)
+	return append(box, data...)
+}
+
+// testEsds builds an esds payload around an AudioSpecificConfig
+func testEsds(config []byte) []byte {
+	decSpecific := append([]byte{decSpecificInfoTag, byte(len(config))}, config...)
+	decoderConfig := append([]byte{decoderConfigDescrTag, byte(13 + len(decSpecific)), 0x40, 0x15}, make([]byte, 11)...)
+	decoderConfig = append(decoderConfig, decSpecific...)
+	es := append([]byte{esDescrTag, byte(3 + len(decoderConfig)), 0, 1, 0}, decoderConfig...)
+	return append([]byte{0, 0, 0, 0}, es...)
+}
+
+// testSoundEntry builds an stsd payload with one mp4a sound sample entry
+func testSoundEntry(version uint16, channelCount uint16, v2Channels uint32, children ...[]byte) []byte {
+	fields := make([]byte, 28) // everything after the entry header up to the children (v0)
+	binary.BigEndian.PutUint16(fields[6:], 1)
+	binary.BigEndian.PutUint16(fields[8:], version)
+	binary.BigEndian.PutUint16(fields[16:], channelCount)
+	switch version {
+	case 1:
+		fields = append(fields, make([]byte, 16)...)
+	case 2:
+		extension := make([]byte, 36)
+		binary.BigEndian.PutUint32(extension[0:], 72)
+		binary.BigEndian.PutUint32(extension[12:], v2Channels)
+		fields = append(fields, extension...)
+	}
+	entry := testBox("mp4a", append([][]byte{fields}, children...)...)
+	return append([]byte{0, 0, 0, 0, 0, 0, 0, 1}, entry...)
+}
+
+func TestEsdsAudioChannels(t *testing.T) {
+	tests := []struct {
+		name   string
+		config []byte
+		want   int
+	}{
+		{"stereo", []byte{0x11, 0x90}, 2},                 // AAC-LC, 48 kHz, config 2
+		{"5.1", []byte{0x11, 0xB0}, 6},                    // config 6
+		{"7.1", []byte{0x11, 0xB8}, 8},                    // config 7 = 8 channels
+		{"program config element", []byte{0x11, 0x80}, 0}, // config 0
+		{"explicit frequency", []byte{0x17, 0x80, 0x00, 0x00, 0x10}, 2},
+		{"truncated", []byte{0x11}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := esdsAudioChannels(testEsds(tt.config)); got != tt.want {
+				t.Errorf("esdsAudioChannels() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMp4ParseStsdChannels(t *testing.T) {
+	surround := testBox("esds", testEsds([]byte{0x11, 0xB0}))
+	tests := []struct {
+		name string
+		stsd []byte
+		want int
+	}{
+		{"v0 channelcount", testSoundEntry(0, 2, 0), 2},
+		{"v0 esds overrides channelcount", testSoundEntry(0, 2, 0, surround), 6},
+		{"v1 esds inside wave", testSoundEntry(1, 2, 0, testBox("wave", surround)), 6},
+		{"v2 extension", testSoundEntry(2, 3, 6), 6},
+		{"v2 esds", testSoundEntry(2, 3, 2, surround), 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample := mp4ParseStsd(bytes.NewReader(tt.stsd), int64(len(tt.stsd)), 0, "soun")
+			if sample.codec != "mp4a" || sample.channels != tt.want {
+				t.Errorf("mp4ParseStsd() = %q with %d channels, want mp4a with %d", sample.codec, sample.channels, tt.want)
+			}
+		})
+	}
+}
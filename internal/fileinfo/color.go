@@ -0,0 +1,183 @@
+package fileinfo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// colorPrimariesNames maps ISO/IEC 23091-2 colour primaries code points to FFmpeg names
+var colorPrimariesNames = map[int]string{
+	1:  "bt709",
+	4:  "bt470m",
+	5:  "bt470bg",
+	6:  "smpte170m",
+	7:  "smpte240m",
+	8:  "film",
+	9:  "bt2020",
+	10: "smpte428",
+	11: "smpte431",
+	12: "smpte432",
+	22: "jedec-p22",
+}
+
+// colorTransferNames maps ISO/IEC 23091-2 transfer characteristics code points to FFmpeg names
+var colorTransferNames = map[int]string{
+	1:  "bt709",
+	4:  "gamma22",
+	5:  "gamma28",
+	6:  "smpte170m",
+	7:  "smpte240m",
+	8:  "linear",
+	11: "iec61966-2-4",
+	13: "iec61966-2-1",
+	14: "bt2020-10",
+	15: "bt2020-12",
+	16: "smpte2084",
+	18: "arib-std-b67",
+}
+
+// colorMatrixNames maps ISO/IEC 23091-2 matrix coefficients code points to FFmpeg names
+var colorMatrixNames = map[int]string{
+	0:  "gbr",
+	1:  "bt709",
+	5:  "bt470bg",
+	6:  "smpte170m",
+	7:  "smpte240m",
+	9:  "bt2020nc",
+	10: "bt2020c",
+	14: "ictcp",
+}
+
+// videoColor holds the color properties read from container metadata
+type videoColor struct {
+	primaries     string
+	transfer      string
+	space         string
+	colorRange    string
+	masterDisplay string
+	maxCLL        int
+	maxFALL       int
+}
+
+// applyTo copies the color properties into the file info
+func (c *videoColor) applyTo(info *FileInfo) {
+	info.ColorPrimaries = c.primaries
+	info.ColorTransfer = c.transfer
+	info.ColorSpace = c.space
+	info.ColorRange = c.colorRange
+	info.MasterDisplay = c.masterDisplay
+	info.MaxCLL = c.maxCLL
+	info.MaxFALL = c.maxFALL
+}
+
+// IsHDR reports whether the video uses an HDR transfer function (PQ or HLG)
+func (f *FileInfo) IsHDR() bool {
+	return f.ColorTransfer == "smpte2084" || f.ColorTransfer == "arib-std-b67"
+}
+
+// formatMasterDisplay formats SMPTE ST 2086 mastering display values in the
+// x265 master-display syntax. Chromaticities are in 0.00002 units and
+// luminance in 0.0001 cd/m² units, in G, B, R order.
+func formatMasterDisplay(gx, gy, bx, by, rx, ry, wx, wy int, maxLum, minLum int64) string {
+	return fmt.Sprintf("G(%d,%d)B(%d,%d)R(%d,%d)WP(%d,%d)L(%d,%d)", gx, gy, bx, by, rx, ry, wx, wy, maxLum, minLum)
+}
+
+// mp4ParseColr reads an MP4 colr box (nclx or QuickTime nclc)
+func mp4ParseColr(data []byte, color *videoColor) {
+	if len(data) < 10 {
+		return
+	}
+	colourType := string(data[0:4])
+	if colourType != "nclx" && colourType != "nclc" {
+		return // ICC profiles are not mapped
+	}
+
+	color.primaries = colorPrimariesNames[int(binary.BigEndian.Uint16(data[4:6]))]
+	color.transfer = colorTransferNames[int(binary.BigEndian.Uint16(data[6:8]))]
+	color.space = colorMatrixNames[int(binary.BigEndian.Uint16(data[8:10]))]
+
+	// Only nclx carries the full range flag; nclc is always limited range
+	color.colorRange = "tv"
+	if colourType == "nclx" && len(data) >= 11 && data[10]&0x80 != 0 {
+		color.colorRange = "pc"
+	}
+}
+
+// mp4ParseMdcv reads an MP4 mdcv (mastering display colour volume) box
+func mp4ParseMdcv(data []byte) string {
+	if len(data) < 24 {
+		return ""
+	}
+	u16 := func(i int) int { return int(binary.BigEndian.Uint16(data[i : i+2])) }
+	return formatMasterDisplay(
+		u16(0), u16(2), // G
+		u16(4), u16(6), // B
+		u16(8), u16(10), // R
+		u16(12), u16(14), // white point
+		int64(binary.BigEndian.Uint32(data[16:20])),
+		int64(binary.BigEndian.Uint32(data[20:24])),
+	)
+}
+
+// mp4ParseClli reads an MP4 clli (content light level) box
+func mp4ParseClli(data []byte) (maxCLL, maxFALL int) {
+	if len(data) < 4 {
+		return 0, 0
+	}
+	return int(binary.BigEndian.Uint16(data[0:2])), int(binary.BigEndian.Uint16(data[2:4]))
+}
+
+// mkvMasteringMetadata holds the Matroska MasteringMetadata values
+// (chromaticities as 0..1 floats, luminance in cd/m²)
+type mkvMasteringMetadata struct {
+	rx, ry, gx, gy, bx, by float64
+	wx, wy                 float64
+	maxLum, minLum         float64
+}
+
+// masterDisplay converts Matroska mastering metadata to the x265 master-display syntax
+func (m *mkvMasteringMetadata) masterDisplay() string {
+	if m.maxLum == 0 {
+		return ""
+	}
+	c := func(v float64) int { return int(math.Round(v * 50000)) }
+	return formatMasterDisplay(
+		c(m.gx), c(m.gy), c(m.bx), c(m.by), c(m.rx), c(m.ry), c(m.wx), c(m.wy),
+		int64(math.Round(m.maxLum*10000)), int64(math.Round(m.minLum*10000)),
+	)
+}
+
+// mkvRangeName maps the Matroska Range element to FFmpeg range names
+func mkvRangeName(v uint64) string {
+	switch v {
+	case 1:
+		return "tv"
+	case 2:
+		return "pc"
+	default:
+		return ""
+	}
+}
+
+// ffprobeColorName normalizes ffprobe color names ("unknown" = not set)
+func ffprobeColorName(name string) string {
+	if name == "unknown" || name == "unspecified" || name == "reserved" {
+		return ""
+	}
+	return name
+}
+
+// masterDisplay converts ffprobe mastering display side data to the x265 master-display syntax
+func (sd *ffprobeSideData) masterDisplay() string {
+	maxLum := parseFramerate(sd.MaxLuminance)
+	if maxLum == 0 {
+		return ""
+	}
+	c := func(s string) int { return int(math.Round(parseFramerate(s) * 50000)) }
+	return formatMasterDisplay(
+		c(sd.GreenX), c(sd.GreenY), c(sd.BlueX), c(sd.BlueY), c(sd.RedX), c(sd.RedY),
+		c(sd.WhitePointX), c(sd.WhitePointY),
+		int64(math.Round(maxLum*10000)), int64(math.Round(parseFramerate(sd.MinLuminance)*10000)),
+	)
+}
@@ -53,17 +53,53 @@ func getFFprobePath() string {
 
 // FileInfo represents video file information
 type FileInfo struct {
-	Path                string  `json:"path"`
-	Name                string  `json:"name"`
-	Width               int     `json:"width"`
-	Height              int     `json:"height"`
-	Duration            string  `json:"duration"`            // "HH:MM:SS" format
-	DurationSeconds     float64 `json:"durationSeconds"`     // seconds (for duration comparison)
-	Framerate           float64 `json:"framerate"`           // e.g., 29.97, 60.0
-	Codec               string  `json:"codec"`               // e.g., "h264", "hevc"
-	AudioCodec          string  `json:"audioCodec"`          // e.g., "aac", "ac3"
-	FileSize            int64   `json:"fileSize"`            // bytes
-	HasDurationMismatch bool    `json:"hasDurationMismatch"` // true if duration differs from other files
+	Path                string       `json:"path"`
+	Name                string       `json:"name"`
+	Width               int          `json:"width"`
+	Height              int          `json:"height"`
+	Duration            string       `json:"duration"`            // "HH:MM:SS" format
+	DurationSeconds     float64      `json:"durationSeconds"`     // seconds (for duration comparison)
+	Framerate           float64      `json:"framerate"`           // e.g., 29.97, 60.0
+	Codec               string       `json:"codec"`               // e.g., "h264", "hevc"
+	AudioCodec          string       `json:"audioCodec"`          // e.g., "aac", "ac3"
+	AudioTracks         []AudioTrack `json:"audioTracks"`         // all audio tracks in source order
+	AudioTracksKnown    bool         `json:"audioTracksKnown"`    // true if the stream list was fully read, so no tracks means no audio
+	AudioSkewMs         int          `json:"audioSkewMs"`         // existing audio start relative to video from edit lists/CodecDelay (+ = audio later)
+	BitDepth            int          `json:"bitDepth"`            // luma bit depth, e.g., 8, 10 (0 = unknown)
+	ColorPrimaries      string       `json:"colorPrimaries"`      // e.g., "bt709", "bt2020" ("" = unknown)
+	ColorTransfer       string       `json:"colorTransfer"`       // e.g., "bt709", "smpte2084", "arib-std-b67"
+	ColorSpace          string       `json:"colorSpace"`          // matrix coefficients, e.g., "bt709", "bt2020nc"
+	ColorRange          string       `json:"colorRange"`          // "tv" (limited) or "pc" (full)
+	MasterDisplay       string       `json:"masterDisplay"`       // HDR10 mastering display in x265 syntax ("" = none)
+	MaxCLL              int          `json:"maxCll"`              // HDR10 max content light level in cd/m² (0 = none)
+	MaxFALL             int          `json:"maxFall"`             // HDR10 max frame-average light level in cd/m²
+	FileSize            int64        `json:"fileSize"`            // bytes
+	TrimmedSeconds      float64      `json:"trimmedSeconds"`      // length after in/out points and sync trims (0 = untrimmed)
+	HasDurationMismatch bool         `json:"hasDurationMismatch"` // true if duration differs from other files
+}
+
+// AudioTrack describes one audio track of the source
+type AudioTrack struct {
+	Index    int    `json:"index"`    // position among the audio tracks (0 = first), as used by -map 0:a:N
+	Codec    string `json:"codec"`    // e.g., "aac", "ac3"
+	Channels int    `json:"channels"` // channel count (0 = unknown)
+	Language string `json:"language"` // ISO 639-2 code, e.g., "eng", "kor" ("" = unknown)
+}
+
+// addAudioTrack appends an audio track and keeps AudioCodec pointing at the first one
+func (f *FileInfo) addAudioTrack(codec string, channels int, language string) {
+	if language == "und" {
+		language = ""
+	}
+	f.AudioTracks = append(f.AudioTracks, AudioTrack{
+		Index:    len(f.AudioTracks),
+		Codec:    codec,
+		Channels: channels,
+		Language: language,
+	})
+	if f.AudioCodec == "" {
+		f.AudioCodec = codec
+	}
 }
 
 // DurationCheckResult represents the result of duration mismatch check
@@ -89,12 +125,38 @@ type ffprobeOutput struct {
 }
 
 type ffprobeStream struct {
-	CodecType     string `json:"codec_type"`
-	CodecName     string `json:"codec_name"`
-	Width         int    `json:"width"`
-	Height        int    `json:"height"`
-	RFrameRate    string `json:"r_frame_rate"`    // e.g., "30000/1001"
-	AvgFrameRate  string `json:"avg_frame_rate"`  // e.g., "30000/1001"
+	CodecType      string            `json:"codec_type"`
+	CodecName      string            `json:"codec_name"`
+	Width          int               `json:"width"`
+	Height         int               `json:"height"`
+	RFrameRate     string            `json:"r_frame_rate"`    // e.g., "30000/1001"
+	AvgFrameRate   string            `json:"avg_frame_rate"`  // e.g., "30000/1001"
+	PixFmt         string            `json:"pix_fmt"`         // e.g., "yuv420p10le"
+	ColorRange     string            `json:"color_range"`     // e.g., "tv"
+	ColorSpace     string            `json:"color_space"`     // e.g., "bt2020nc"
+	ColorTransfer  string            `json:"color_transfer"`  // e.g., "smpte2084"
+	ColorPrimaries string            `json:"color_primaries"` // e.g., "bt2020"
+	SideData       []ffprobeSideData `json:"side_data_list"`
+	Channels       int               `json:"channels"`   // audio only
+	Tags           map[string]string `json:"tags"`       // e.g., {"language": "eng"}
+	StartTime      string            `json:"start_time"` // e.g., "0.021333"
+}
+
+// ffprobeSideData represents stream side data (HDR10 mastering display and light level)
+type ffprobeSideData struct {
+	Type         string `json:"side_data_type"`
+	RedX         string `json:"red_x"` // rationals, e.g., "35400/50000"
+	RedY         string `json:"red_y"`
+	GreenX       string `json:"green_x"`
+	GreenY       string `json:"green_y"`
+	BlueX        string `json:"blue_x"`
+	BlueY        string `json:"blue_y"`
+	WhitePointX  string `json:"white_point_x"`
+	WhitePointY  string `json:"white_point_y"`
+	MaxLuminance string `json:"max_luminance"` // e.g., "10000000/10000"
+	MinLuminance string `json:"min_luminance"`
+	MaxContent   int    `json:"max_content"`
+	MaxAverage   int    `json:"max_average"`
 }
 
 type ffprobeFormat struct {
@@ -112,17 +174,17 @@ func GetFileInfo(path string) (*FileInfo, error) {
 	case ".mp4", ".mov", ".m4v":
 		info, err := parseMP4(path)
 		if err == nil {
-			return info, nil
+			return confirmAudioTracks(info), nil
 		}
 	case ".mkv", ".webm":
 		info, err := parseMKV(path)
 		if err == nil {
-			return info, nil
+			return confirmAudioTracks(info), nil
 		}
 	case ".avi":
 		info, err := parseAVI(path)
 		if err == nil {
-			return info, nil
+			return confirmAudioTracks(info), nil
 		}
 	}
 
@@ -161,12 +223,14 @@ func getFileInfoFFprobe(path string) (*FileInfo, error) {
 	}
 
 	info := &FileInfo{
-		Path:     path,
-		Name:     filepath.Base(path),
-		FileSize: stat.Size(),
+		Path:             path,
+		Name:             filepath.Base(path),
+		FileSize:         stat.Size(),
+		AudioTracksKnown: true, // ffprobe lists every stream
 	}
 
 	// Find video and audio streams
+	var videoStart, audioStart string
 	for _, stream := range probe.Streams {
 		if stream.CodecType == "video" && info.Codec == "" {
 			info.Width = stream.Width
@@ -176,9 +240,35 @@ func getFileInfoFFprobe(path string) (*FileInfo, error) {
 			if info.Framerate == 0 {
 				info.Framerate = parseFramerate(stream.AvgFrameRate)
 			}
+			info.BitDepth = pixFmtBitDepth(stream.PixFmt)
+			videoStart = stream.StartTime
+			info.ColorPrimaries = ffprobeColorName(stream.ColorPrimaries)
+			info.ColorTransfer = ffprobeColorName(stream.ColorTransfer)
+			info.ColorSpace = ffprobeColorName(stream.ColorSpace)
+			info.ColorRange = ffprobeColorName(stream.ColorRange)
+			for _, sd := range stream.SideData {
+				switch sd.Type {
+				case "Mastering display metadata":
+					info.MasterDisplay = sd.masterDisplay()
+				case "Content light level metadata":
+					info.MaxCLL, info.MaxFALL = sd.MaxContent, sd.MaxAverage
+				}
+			}
 		}
-		if stream.CodecType == "audio" && info.AudioCodec == "" {
-			info.AudioCodec = stream.CodecName
+		if stream.CodecType == "audio" {
+			if len(info.AudioTracks) == 0 {
+				audioStart = stream.StartTime
+			}
+			info.addAudioTrack(stream.CodecName, stream.Channels, stream.Tags["language"])
+		}
+	}
+
+	// Skew between the first audio and video stream start times
+	if videoStart != "" && audioStart != "" {
+		v, errV := strconv.ParseFloat(videoStart, 64)
+		a, errA := strconv.ParseFloat(audioStart, 64)
+		if errV == nil && errA == nil {
+			info.AudioSkewMs = int(math.Round((a - v) * 1000))
 		}
 	}
 
@@ -192,6 +282,40 @@ func getFileInfoFFprobe(path string) (*FileInfo, error) {
 	return info, nil
 }
 
+// confirmAudioTracks marks the audio tracks of a natively parsed file as known.
+// A native parser finding no audio may have missed a stream it doesn't understand,
+// so that case is confirmed with ffprobe; if ffprobe fails the tracks stay unknown.
+func confirmAudioTracks(info *FileInfo) *FileInfo {
+	if len(info.AudioTracks) > 0 {
+		info.AudioTracksKnown = true
+		return info
+	}
+
+	cmd := exec.Command(getFFprobePath(),
+		"-v", "quiet",
+		"-print_format", "json",
+		"-show_streams",
+		"-select_streams", "a",
+		info.Path,
+	)
+	cmdutil.HideWindow(cmd)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return info
+	}
+	var probe ffprobeOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return info
+	}
+
+	for _, stream := range probe.Streams {
+		info.addAudioTrack(stream.CodecName, stream.Channels, stream.Tags["language"])
+	}
+	info.AudioTracksKnown = true
+	return info
+}
+
 // parseFramerate parses a framerate string like "30000/1001" or "30"
 func parseFramerate(s string) float64 {
 	if s == "" || s == "0/0" {
@@ -219,7 +343,16 @@ func formatDuration(seconds float64) string {
 	return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, secs)
 }
 
-// CheckDurationMismatch checks if files have different durations
+// EffectiveDuration returns the length that will be encoded: the trimmed length when
+// the file is trimmed, otherwise the full duration
+func (f *FileInfo) EffectiveDuration() float64 {
+	if f.TrimmedSeconds > 0 {
+		return f.TrimmedSeconds
+	}
+	return f.DurationSeconds
+}
+
+// CheckDurationMismatch checks if files have different durations after trimming
 // tolerance is in seconds (default 1 second)
 func CheckDurationMismatch(files []*FileInfo, tolerance float64) DurationCheckResult {
 	if tolerance <= 0 {
@@ -237,20 +370,21 @@ func CheckDurationMismatch(files []*FileInfo, tolerance float64) DurationCheckRe
 	}
 
 	// Use first file as base
-	baseDuration := files[0].DurationSeconds
-	result.BaseDuration = files[0].Duration
+	baseDuration := files[0].EffectiveDuration()
+	result.BaseDuration = formatDuration(baseDuration)
 
 	for _, file := range files {
-		diff := file.DurationSeconds - baseDuration
-		if math.Abs(diff) > tolerance {
+		diff := file.EffectiveDuration() - baseDuration
+		// Reset the flag, since trimming can resolve a mismatch
+		file.HasDurationMismatch = math.Abs(diff) > tolerance
+		if file.HasDurationMismatch {
 			result.HasMismatch = true
-			file.HasDurationMismatch = true
 
 			diffStr := formatDiff(diff)
 			result.MismatchFiles = append(result.MismatchFiles, DurationMismatchInfo{
 				Path:     file.Path,
 				Name:     file.Name,
-				Duration: file.Duration,
+				Duration: formatDuration(file.EffectiveDuration()),
 				Diff:     diffStr,
 			})
 		}
@@ -24,7 +24,20 @@ const (
 	ebmlPixelWidth     = 0xB0
 	ebmlPixelHeight    = 0xBA
 	ebmlAudio          = 0xE1
+	ebmlChannels       = 0x9F
+	ebmlLanguage       = 0x22B59C
+	ebmlCodecDelay     = 0x56AA
 	ebmlDefaultDur     = 0x23E383
+	ebmlCodecPrivate   = 0x63A2
+	ebmlColour         = 0x55B0
+	ebmlBitsPerChannel = 0x55B2
+	ebmlMatrixCoeffs   = 0x55B1
+	ebmlColourRange    = 0x55B9
+	ebmlTransferChar   = 0x55BA
+	ebmlPrimaries      = 0x55BB
+	ebmlMaxCLL         = 0x55BC
+	ebmlMaxFALL        = 0x55BD
+	ebmlMastering      = 0x55D0
 )
 
 // parseMKV parses MKV/WebM files natively without ffprobe
@@ -320,8 +333,12 @@ func mkvParseTrackEntry(r io.ReadSeeker, size uint64, offset int64, info *FileIn
 
 	var trackType uint64
 	var codecID string
-	var width, height int
+	var codecPrivate []byte
+	var video mkvVideoInfo
 	var defaultDuration uint64
+	channels := 1     // Matroska default
+	language := "eng" // Matroska default
+	var codecDelay uint64
 
 	for {
 		pos, _ := r.Seek(0, io.SeekCurrent)
@@ -352,10 +369,29 @@ func mkvParseTrackEntry(r io.ReadSeeker, size uint64, offset int64, info *FileIn
 			if err == nil {
 				defaultDuration = val
 			}
+		case ebmlCodecPrivate:
+			if sz <= 64*1024 {
+				data := make([]byte, sz)
+				if _, err := io.ReadFull(r, data); err == nil {
+					codecPrivate = data
+				}
+			}
 		case ebmlVideo:
-			w, h := mkvParseVideoInfo(r, sz, dataPos)
-			width = w
-			height = h
+			video = mkvParseVideoInfo(r, sz, dataPos)
+		case ebmlAudio:
+			if val := mkvFindUint(r, sz, dataPos, ebmlChannels); val > 0 {
+				channels = int(val)
+			}
+		case ebmlCodecDelay:
+			val, err := ebmlReadUint(r, sz)
+			if err == nil {
+				codecDelay = val
+			}
+		case ebmlLanguage:
+			val, err := ebmlReadString(r, sz)
+			if err == nil {
+				language = val
+			}
 		}
 
 		r.Seek(dataPos+int64(sz), io.SeekStart)
@@ -364,20 +400,44 @@ func mkvParseTrackEntry(r io.ReadSeeker, size uint64, offset int64, info *FileIn
 	// trackType 1 = video, 2 = audio
 	if trackType == 1 && info.Codec == "" {
 		info.Codec = mkvCodecName(codecID)
-		info.Width = width
-		info.Height = height
+		info.Width = video.width
+		info.Height = video.height
+		video.color.applyTo(info)
+
+		// CodecPrivate carries the hvcC/avcC record; Colour is the fallback
+		switch {
+		case info.Codec == "hevc" && len(codecPrivate) > 0:
+			info.BitDepth = hevcConfigBitDepth(codecPrivate)
+		case info.Codec == "h264" && len(codecPrivate) > 0:
+			info.BitDepth = avcConfigBitDepth(codecPrivate)
+		default:
+			info.BitDepth = video.bitsPerChannel
+		}
 		if defaultDuration > 0 {
 			info.Framerate = math.Round(1e9/float64(defaultDuration)*100) / 100
 		}
 	}
 
-	if trackType == 2 && info.AudioCodec == "" {
-		info.AudioCodec = mkvAudioCodecName(codecID)
+	if trackType == 2 {
+		// CodecDelay (ns) is subtracted from block timestamps, so the first track's audio plays earlier
+		if len(info.AudioTracks) == 0 && codecDelay > 0 {
+			info.AudioSkewMs = -int(math.Round(float64(codecDelay) / 1e6))
+		}
+		info.addAudioTrack(mkvAudioCodecName(codecID), channels, language)
 	}
 }
 
+// mkvVideoInfo holds the fields read from the Video sub-element
+type mkvVideoInfo struct {
+	width          int
+	height         int
+	bitsPerChannel int        // from Colour (0 = unknown)
+	color          videoColor // from Colour
+}
+
 // mkvParseVideoInfo parses the Video sub-element
-func mkvParseVideoInfo(r io.ReadSeeker, size uint64, offset int64) (width, height int) {
+func mkvParseVideoInfo(r io.ReadSeeker, size uint64, offset int64) mkvVideoInfo {
+	var video mkvVideoInfo
 	r.Seek(offset, io.SeekStart)
 	end := offset + int64(size)
 
@@ -398,19 +458,146 @@ func mkvParseVideoInfo(r io.ReadSeeker, size uint64, offset int64) (width, heigh
 		case ebmlPixelWidth:
 			val, err := ebmlReadUint(r, sz)
 			if err == nil {
-				width = int(val)
+				video.width = int(val)
 			}
 		case ebmlPixelHeight:
 			val, err := ebmlReadUint(r, sz)
 			if err == nil {
-				height = int(val)
+				video.height = int(val)
+			}
+		case ebmlColour:
+			mkvParseColour(r, sz, dataPos, &video)
+		}
+
+		r.Seek(dataPos+int64(sz), io.SeekStart)
+	}
+
+	return video
+}
+
+// mkvParseColour parses the Colour sub-element of Video
+func mkvParseColour(r io.ReadSeeker, size uint64, offset int64, video *mkvVideoInfo) {
+	r.Seek(offset, io.SeekStart)
+	end := offset + int64(size)
+
+	for {
+		pos, _ := r.Seek(0, io.SeekCurrent)
+		if pos >= end {
+			break
+		}
+
+		id, sz, err := ebmlReadElement(r)
+		if err != nil {
+			break
+		}
+
+		dataPos, _ := r.Seek(0, io.SeekCurrent)
+
+		switch id {
+		case ebmlBitsPerChannel:
+			val, err := ebmlReadUint(r, sz)
+			if err == nil {
+				video.bitsPerChannel = int(val)
+			}
+		case ebmlMatrixCoeffs, ebmlColourRange, ebmlTransferChar, ebmlPrimaries, ebmlMaxCLL, ebmlMaxFALL:
+			val, err := ebmlReadUint(r, sz)
+			if err == nil {
+				mkvSetColourValue(&video.color, id, val)
+			}
+		case ebmlMastering:
+			video.color.masterDisplay = mkvParseMastering(r, sz, dataPos)
+		}
+
+		r.Seek(dataPos+int64(sz), io.SeekStart)
+	}
+}
+
+// mkvFindUint returns the value of an unsigned child element (0 = not found)
+func mkvFindUint(r io.ReadSeeker, size uint64, offset int64, target uint64) uint64 {
+	r.Seek(offset, io.SeekStart)
+	end := offset + int64(size)
+
+	for {
+		pos, _ := r.Seek(0, io.SeekCurrent)
+		if pos >= end {
+			break
+		}
+
+		id, sz, err := ebmlReadElement(r)
+		if err != nil {
+			break
+		}
+
+		dataPos, _ := r.Seek(0, io.SeekCurrent)
+
+		if id == target {
+			val, err := ebmlReadUint(r, sz)
+			if err == nil {
+				return val
+			}
+		}
+
+		r.Seek(dataPos+int64(sz), io.SeekStart)
+	}
+
+	return 0
+}
+
+// mkvSetColourValue stores an unsigned Colour element in the color info
+func mkvSetColourValue(color *videoColor, id uint64, val uint64) {
+	switch id {
+	case ebmlMatrixCoeffs:
+		color.space = colorMatrixNames[int(val)]
+	case ebmlColourRange:
+		color.colorRange = mkvRangeName(val)
+	case ebmlTransferChar:
+		color.transfer = colorTransferNames[int(val)]
+	case ebmlPrimaries:
+		color.primaries = colorPrimariesNames[int(val)]
+	case ebmlMaxCLL:
+		color.maxCLL = int(val)
+	case ebmlMaxFALL:
+		color.maxFALL = int(val)
+	}
+}
+
+// mkvParseMastering parses the MasteringMetadata sub-element of Colour
+func mkvParseMastering(r io.ReadSeeker, size uint64, offset int64) string {
+	var m mkvMasteringMetadata
+	fields := map[uint64]*float64{
+		0x55D1: &m.rx, 0x55D2: &m.ry,
+		0x55D3: &m.gx, 0x55D4: &m.gy,
+		0x55D5: &m.bx, 0x55D6: &m.by,
+		0x55D7: &m.wx, 0x55D8: &m.wy,
+		0x55D9: &m.maxLum, 0x55DA: &m.minLum,
+	}
+
+	r.Seek(offset, io.SeekStart)
+	end := offset + int64(size)
+
+	for {
+		pos, _ := r.Seek(0, io.SeekCurrent)
+		if pos >= end {
+			break
+		}
+
+		id, sz, err := ebmlReadElement(r)
+		if err != nil {
+			break
+		}
+
+		dataPos, _ := r.Seek(0, io.SeekCurrent)
+
+		if field, ok := fields[id]; ok {
+			if val, err := ebmlReadFloat(r, sz); err == nil {
+				*field = val
 			}
 		}
 
 		r.Seek(dataPos+int64(sz), io.SeekStart)
 	}
 
-	return width, height
+	return m.masterDisplay()
 }
 
 // mkvCodecName maps Matroska CodecID to human-readable names
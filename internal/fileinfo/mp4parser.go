@@ -102,8 +102,10 @@ func mp4ParseMoov(r io.ReadSeeker, size int64, offset int64, info *FileInfo) err
 
 	var movieTimescale uint32
 	var movieDuration uint64
+	var videoStart, audioStart float64
+	var hasVideo, hasAudio bool
 
-	return mp4IterateBoxes(r, size, offset, func(boxType string, dataSize int64, dataOffset int64) error {
+	err := mp4IterateBoxes(r, size, offset, func(boxType string, dataSize int64, dataOffset int64) error {
 		switch boxType {
 		case "mvhd":
 			ts, dur, err := mp4ParseMvhd(r, dataSize, dataOffset)
@@ -117,10 +119,23 @@ func mp4ParseMoov(r io.ReadSeeker, size int64, offset int64, info *FileInfo) err
 				}
 			}
 		case "trak":
-			mp4ParseTrak(r, dataSize, dataOffset, info, movieTimescale)
+			handlerType, start := mp4ParseTrak(r, dataSize, dataOffset, info, movieTimescale)
+			switch {
+			case handlerType == "vide" && !hasVideo:
+				videoStart, hasVideo = start, true
+			case handlerType == "soun" && !hasAudio:
+				audioStart, hasAudio = start, true
+			}
 		}
 		return nil
 	})
+
+	// Edit lists shift each track's presentation start; report the audio skew they cause
+	if hasVideo && hasAudio {
+		info.AudioSkewMs = int(math.Round((audioStart - videoStart) * 1000))
+	}
+
+	return err
 }
 
 // mp4ParseMvhd parses the movie header box
@@ -155,16 +170,19 @@ func mp4ParseMvhd(r io.ReadSeeker, size int64, offset int64) (timescale uint32,
 	return timescale, duration, nil
 }
 
-// mp4ParseTrak parses a track container
-func mp4ParseTrak(r io.ReadSeeker, size int64, offset int64, info *FileInfo, movieTimescale uint32) {
+// mp4ParseTrak parses a track container and returns its handler type and
+// presentation start in seconds from the edit list
+func mp4ParseTrak(r io.ReadSeeker, size int64, offset int64, info *FileInfo, movieTimescale uint32) (string, float64) {
 	r.Seek(offset, io.SeekStart)
 
 	var trackWidth, trackHeight int
 	var handlerType string
-	var codec string
+	var sample mp4SampleEntry
 	var mediaTimescale uint32
 	var sampleCount uint32
 	var mediaDuration uint64
+	var language string
+	var edit mp4Edit
 
 	mp4IterateBoxes(r, size, offset, func(boxType string, dataSize int64, dataOffset int64) error {
 		switch boxType {
@@ -172,14 +190,23 @@ func mp4ParseTrak(r io.ReadSeeker, size int64, offset int64, info *FileInfo, mov
 			w, h := mp4ParseTkhd(r, dataSize, dataOffset)
 			trackWidth = w
 			trackHeight = h
+		case "edts":
+			r.Seek(dataOffset, io.SeekStart)
+			mp4IterateBoxes(r, dataSize, dataOffset, func(boxType string, dataSize int64, dataOffset int64) error {
+				if boxType == "elst" {
+					edit = mp4ParseElst(r, dataSize, dataOffset)
+				}
+				return nil
+			})
 		case "mdia":
 			r.Seek(dataOffset, io.SeekStart)
 			mp4IterateBoxes(r, dataSize, dataOffset, func(boxType string, dataSize int64, dataOffset int64) error {
 				switch boxType {
 				case "mdhd":
-					ts, dur := mp4ParseMdhd(r, dataSize, dataOffset)
+					ts, dur, lang := mp4ParseMdhd(r, dataSize, dataOffset)
 					mediaTimescale = ts
 					mediaDuration = dur
+					language = lang
 				case "hdlr":
 					handlerType = mp4ParseHdlr(r, dataSize, dataOffset)
 				case "minf":
@@ -190,7 +217,7 @@ func mp4ParseTrak(r io.ReadSeeker, size int64, offset int64, info *FileInfo, mov
 							mp4IterateBoxes(r, dataSize, dataOffset, func(boxType string, dataSize int64, dataOffset int64) error {
 								switch boxType {
 								case "stsd":
-									codec = mp4ParseStsd(r, dataSize, dataOffset)
+									sample = mp4ParseStsd(r, dataSize, dataOffset, handlerType)
 								case "stts":
 									sampleCount = mp4ParseStts(r, dataSize, dataOffset)
 								}
@@ -207,9 +234,11 @@ func mp4ParseTrak(r io.ReadSeeker, size int64, offset int64, info *FileInfo, mov
 	})
 
 	if handlerType == "vide" && info.Codec == "" {
-		info.Codec = mp4CodecName(codec)
+		info.Codec = mp4CodecName(sample.codec)
 		info.Width = trackWidth
 		info.Height = trackHeight
+		info.BitDepth = sample.bitDepth
+		sample.color.applyTo(info)
 
 		// Calculate framerate from media timescale and sample count
 		if mediaTimescale > 0 && sampleCount > 0 && mediaDuration > 0 {
@@ -221,9 +250,65 @@ func mp4ParseTrak(r io.ReadSeeker, size int64, offset int64, info *FileInfo, mov
 		}
 	}
 
-	if handlerType == "soun" && info.AudioCodec == "" {
-		info.AudioCodec = mp4AudioCodecName(codec)
+	if handlerType == "soun" {
+		info.addAudioTrack(mp4AudioCodecName(sample.codec), sample.channels, language)
+	}
+
+	// Empty edits delay the track; the media time skips into it
+	var start float64
+	if movieTimescale > 0 {
+		start += float64(edit.emptyDuration) / float64(movieTimescale)
+	}
+	if mediaTimescale > 0 {
+		start -= float64(edit.mediaTime) / float64(mediaTimescale)
+	}
+	return handlerType, start
+}
+
+// mp4Edit holds the start of a track's edit list
+type mp4Edit struct {
+	emptyDuration uint64 // total leading empty edits in movie timescale units
+	mediaTime     int64  // media time of the first non-empty edit in media timescale units
+}
+
+// mp4ParseElst parses an edit list box up to its first non-empty edit
+func mp4ParseElst(r io.ReadSeeker, size int64, offset int64) mp4Edit {
+	var edit mp4Edit
+	data := mp4ReadBoxData(r, size, offset)
+	if len(data) < 8 {
+		return edit
+	}
+
+	version := data[0]
+	count := int(binary.BigEndian.Uint32(data[4:8]))
+	entrySize := 12 // segment_duration(4) + media_time(4) + media_rate(4)
+	if version == 1 {
+		entrySize = 20 // segment_duration(8) + media_time(8) + media_rate(4)
 	}
+
+	for i := 0; i < count; i++ {
+		pos := 8 + i*entrySize
+		if pos+entrySize > len(data) {
+			break
+		}
+		var duration uint64
+		var mediaTime int64
+		if version == 1 {
+			duration = binary.BigEndian.Uint64(data[pos : pos+8])
+			mediaTime = int64(binary.BigEndian.Uint64(data[pos+8 : pos+16]))
+		} else {
+			duration = uint64(binary.BigEndian.Uint32(data[pos : pos+4]))
+			mediaTime = int64(int32(binary.BigEndian.Uint32(data[pos+4 : pos+8])))
+		}
+		if mediaTime == -1 {
+			edit.emptyDuration += duration
+			continue
+		}
+		edit.mediaTime = mediaTime
+		break
+	}
+
+	return edit
 }
 
 // mp4ParseTkhd parses track header for width/height
@@ -251,8 +336,8 @@ func mp4ParseTkhd(r io.ReadSeeker, size int64, offset int64) (width, height int)
 	return width, height
 }
 
-// mp4ParseMdhd parses media header for timescale and duration
-func mp4ParseMdhd(r io.ReadSeeker, size int64, offset int64) (timescale uint32, duration uint64) {
+// mp4ParseMdhd parses media header for timescale, duration and language
+func mp4ParseMdhd(r io.ReadSeeker, size int64, offset int64) (timescale uint32, duration uint64, language string) {
 	r.Seek(offset, io.SeekStart)
 
 	var version [1]byte
@@ -272,7 +357,20 @@ func mp4ParseMdhd(r io.ReadSeeker, size int64, offset int64) (timescale uint32,
 		duration = binary.BigEndian.Uint64(tsData[4:12])
 	}
 
-	return timescale, duration
+	// ISO 639-2/T language packed as three 5-bit letters after the duration
+	var lang [2]byte
+	if _, err := io.ReadFull(r, lang[:]); err == nil {
+		packed := binary.BigEndian.Uint16(lang[:])
+		if packed != 0 && packed != 0x7FFF {
+			language = string([]byte{
+				byte(packed>>10&0x1F) + 0x60,
+				byte(packed>>5&0x1F) + 0x60,
+				byte(packed&0x1F) + 0x60,
+			})
+		}
+	}
+
+	return timescale, duration, language
 }
 
 // mp4ParseHdlr parses handler box to get track type
@@ -287,17 +385,138 @@ func mp4ParseHdlr(r io.ReadSeeker, size int64, offset int64) string {
 	return string(handlerType[:])
 }
 
-// mp4ParseStsd parses sample description to get codec
-func mp4ParseStsd(r io.ReadSeeker, size int64, offset int64) string {
-	r.Seek(offset+8, io.SeekStart) // skip version(1)+flags(3)+entry_count(4)
+// mp4SampleEntry holds the fields read from the first sample description entry
+type mp4SampleEntry struct {
+	codec    string
+	bitDepth int        // video only (0 = unknown)
+	channels int        // audio only (0 = unknown)
+	color    videoColor // video only, from colr/mdcv/clli
+}
+
+// mp4VisualEntrySize is the size of a VisualSampleEntry before its child boxes:
+// header(8) + reserved(6) + data_reference_index(2) + visual fields(70)
+const mp4VisualEntrySize = 86
 
-	// Read first sample entry's codec type (skip size(4), read type(4))
+// mp4ParseStsd parses sample description to get codec and codec configuration
+func mp4ParseStsd(r io.ReadSeeker, size int64, offset int64, handlerType string) mp4SampleEntry {
+	entryOffset := offset + 8
+	r.Seek(entryOffset, io.SeekStart) // skip version(1)+flags(3)+entry_count(4)
+
+	// Read first sample entry's size(4) and codec type(4)
 	var entry [8]byte
 	if _, err := io.ReadFull(r, entry[:]); err != nil {
-		return ""
+		return mp4SampleEntry{}
 	}
 
-	return string(entry[4:8])
+	sample := mp4SampleEntry{codec: string(entry[4:8])}
+	entrySize := int64(binary.BigEndian.Uint32(entry[0:4]))
+
+	if handlerType == "vide" && entrySize > mp4VisualEntrySize {
+		// Codec configuration and color boxes (hvcC, avcC, colr, ...) follow the visual fields
+		childOffset := entryOffset + mp4VisualEntrySize
+		r.Seek(childOffset, io.SeekStart)
+		mp4IterateBoxes(r, entrySize-mp4VisualEntrySize, childOffset, func(boxType string, dataSize int64, dataOffset int64) error {
+			switch boxType {
+			case "hvcC":
+				sample.bitDepth = hevcConfigBitDepth(mp4ReadBoxData(r, dataSize, dataOffset))
+			case "avcC":
+				sample.bitDepth = avcConfigBitDepth(mp4ReadBoxData(r, dataSize, dataOffset))
+			case "colr":
+				mp4ParseColr(mp4ReadBoxData(r, dataSize, dataOffset), &sample.color)
+			case "mdcv":
+				sample.color.masterDisplay = mp4ParseMdcv(mp4ReadBoxData(r, dataSize, dataOffset))
+			case "clli":
+				sample.color.maxCLL, sample.color.maxFALL = mp4ParseClli(mp4ReadBoxData(r, dataSize, dataOffset))
+			}
+			return nil
+		})
+	}
+
+	if handlerType == "soun" {
+		sample.channels = mp4AudioChannels(r, entryOffset, entrySize)
+	}
+
+	return sample
+}
+
+// Sizes of a (QuickTime) sound sample description before its child boxes, per version
+const (
+	mp4SoundEntrySizeV0 = 36 // header(8) + reserved(6) + data_reference_index(2) + version(2) + revision(2) + vendor(4) + fields(12)
+	mp4SoundEntrySizeV1 = 52 // v0 + samples/bytes per packet/frame/sample(16)
+	mp4SoundEntrySizeV2 = 72 // v0 + struct size(4) + sample rate(8) + channels(4) + flags and packet fields(20)
+)
+
+// mp4AudioChannels reads the channel count of an audio sample entry. The fixed
+// channelcount field is only a hint: version 2 QuickTime entries always store 3 there
+// and carry the real count in the extension, and AAC stores its layout in the esds
+// AudioSpecificConfig, which is preferred when present.
+func mp4AudioChannels(r io.ReadSeeker, entryOffset int64, entrySize int64) int {
+	if entrySize < mp4SoundEntrySizeV0 {
+		return 0
+	}
+	var fields [mp4SoundEntrySizeV0]byte
+	r.Seek(entryOffset, io.SeekStart)
+	if _, err := io.ReadFull(r, fields[:]); err != nil {
+		return 0
+	}
+
+	channels := int(binary.BigEndian.Uint16(fields[24:26]))
+	childOffset := entryOffset + mp4SoundEntrySizeV0
+	switch binary.BigEndian.Uint16(fields[16:18]) {
+	case 1:
+		childOffset = entryOffset + mp4SoundEntrySizeV1
+	case 2:
+		// channelcount is always 3 here; numAudioChannels follows the struct size and sample rate
+		childOffset = entryOffset + mp4SoundEntrySizeV2
+		if entrySize < mp4SoundEntrySizeV2 {
+			return 0
+		}
+		var count [4]byte
+		r.Seek(entryOffset+48, io.SeekStart)
+		if _, err := io.ReadFull(r, count[:]); err != nil {
+			return 0
+		}
+		channels = int(binary.BigEndian.Uint32(count[:]))
+	}
+
+	// esds sits directly in the entry (ISO) or inside a wave box (QuickTime v1)
+	if childOffset < entryOffset+entrySize {
+		if configChannels := mp4EsdsChannels(r, entryOffset+entrySize-childOffset, childOffset); configChannels > 0 {
+			channels = configChannels
+		}
+	}
+	return channels
+}
+
+// mp4EsdsChannels finds an esds box among the given boxes and returns the channel
+// count of its AudioSpecificConfig (0 = not found or not signalled)
+func mp4EsdsChannels(r io.ReadSeeker, size int64, offset int64) int {
+	channels := 0
+	r.Seek(offset, io.SeekStart)
+	mp4IterateBoxes(r, size, offset, func(boxType string, dataSize int64, dataOffset int64) error {
+		switch boxType {
+		case "esds":
+			channels = esdsAudioChannels(mp4ReadBoxData(r, dataSize, dataOffset))
+		case "wave":
+			channels = mp4EsdsChannels(r, dataSize, dataOffset)
+		}
+		if channels > 0 {
+			return io.EOF // stop iterating
+		}
+		return nil
+	})
+	return channels
+}
+
+// mp4ReadBoxData reads the payload of a small box (capped at 64KB)
+func mp4ReadBoxData(r io.ReadSeeker, size int64, offset int64) []byte {
+	if size > 64*1024 {
+		size = 64 * 1024
+	}
+	r.Seek(offset, io.SeekStart)
+	data := make([]byte, size)
+	n, _ := io.ReadFull(r, data)
+	return data[:n]
 }
 
 // mp4ParseStts parses time-to-sample table to get total sample count
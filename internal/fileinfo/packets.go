@@ -0,0 +1,97 @@
+package fileinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"syncLauperVideoConverter/internal/cmdutil"
+)
+
+// Packet represents a single demuxed packet of a stream
+type Packet struct {
+	PTS      float64 // presentation timestamp in seconds
+	DTS      float64 // decoding timestamp in seconds
+	Size     int     // bytes
+	Keyframe bool
+}
+
+type ffprobePacketOutput struct {
+	Packets []struct {
+		PTSTime string `json:"pts_time"`
+		DTSTime string `json:"dts_time"`
+		Size    string `json:"size"`
+		Flags   string `json:"flags"`
+	} `json:"packets"`
+}
+
+// ProbePackets lists the packets of the first video stream using ffprobe.
+// Packets are returned in decode order.
+func ProbePackets(path string) ([]Packet, error) {
+	return probePackets(path, nil)
+}
+
+// ProbePacketsHashed lists the packets like ProbePackets, but feeds the file to
+// ffprobe through hash, so the whole file is hashed in the same read
+func ProbePacketsHashed(path string, hash io.Writer) ([]Packet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	packets, err := probePackets("pipe:0", io.TeeReader(file, hash))
+	if err != nil {
+		return nil, err
+	}
+	// ffprobe may stop before trailing elements such as Matroska cues
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	return packets, nil
+}
+
+// probePackets runs ffprobe on input, reading it from stdin when stdin is set
+func probePackets(input string, stdin io.Reader) ([]Packet, error) {
+	cmd := exec.Command(getFFprobePath(),
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "packet=pts_time,dts_time,size,flags",
+		"-print_format", "json",
+		input,
+	)
+	cmd.Stdin = stdin
+	cmdutil.HideWindow(cmd)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe error: %v", err)
+	}
+
+	var probe ffprobePacketOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %v", err)
+	}
+
+	packets := make([]Packet, 0, len(probe.Packets))
+	for _, p := range probe.Packets {
+		pts, _ := strconv.ParseFloat(p.PTSTime, 64)
+		dts, err := strconv.ParseFloat(p.DTSTime, 64)
+		if err != nil {
+			dts = pts
+		}
+		size, _ := strconv.Atoi(p.Size)
+		packets = append(packets, Packet{
+			PTS:      pts,
+			DTS:      dts,
+			Size:     size,
+			Keyframe: strings.HasPrefix(p.Flags, "K"),
+		})
+	}
+
+	return packets, nil
+}
@@ -0,0 +1,221 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"syncLauperVideoConverter/internal/config"
+	"syncLauperVideoConverter/internal/encoder"
+)
+
+// historyFile is the append-only JSON-lines history inside the config directory
+const historyFile = "history.jsonl"
+
+// dateLayout is the date format of search filters
+const dateLayout = "2006-01-02"
+
+// mu serializes appends so concurrent job callbacks don't interleave lines
+var mu sync.Mutex
+
+// Entry represents a finished job
+type Entry struct {
+	ID             string                  `json:"id"`
+	Finished       time.Time               `json:"finished"`
+	Source         string                  `json:"source"`
+	Output         string                  `json:"output"`
+	OutputDir      string                  `json:"outputDir"`
+	Status         string                  `json:"status"`
+	Error          string                  `json:"error,omitempty"`
+	Preset         string                  `json:"preset"`
+	EncodeSeconds  float64                 `json:"encodeSeconds"`
+	OutputSize     int64                   `json:"outputSize"`
+	SHA256         string                  `json:"sha256,omitempty"`
+	OutputSettings *encoder.OutputSettings `json:"outputSettings,omitempty"` // effective settings of a completed output
+	Settings       encoder.Settings        `json:"settings"`                 // encoder-wide settings the job ran with
+	Options        encoder.JobOptions      `json:"options"`                  // per-file options
+}
+
+// Filter represents history search criteria. Empty fields match everything.
+type Filter struct {
+	Name   string `json:"name"`   // substring of the source or output file name, case-insensitive
+	From   string `json:"from"`   // first day, "YYYY-MM-DD"
+	To     string `json:"to"`     // last day, "YYYY-MM-DD"
+	Preset string `json:"preset"` // preset name
+	Status string `json:"status"` // job status
+	Limit  int    `json:"limit"`  // maximum entries (0 = all)
+}
+
+// NewEntry creates the history entry of a finished job run with the given settings.
+// Re-queue fields of the options are recorded in the entry itself.
+func NewEntry(job *encoder.EncodingJob, settings encoder.Settings) Entry {
+	options := job.Options
+	options.Settings = nil
+	options.Preset = ""
+	options.OutputDir = ""
+
+	finished := time.Now()
+	return Entry{
+		ID:             fmt.Sprintf("%d", finished.UnixNano()),
+		Finished:       finished,
+		Source:         job.InputPath,
+		Output:         job.OutputPath,
+		OutputDir:      filepath.Dir(job.OutputPath),
+		Status:         job.Status,
+		Error:          job.Error,
+		Preset:         job.Preset.Name,
+		EncodeSeconds:  job.EncodeSeconds,
+		OutputSize:     job.OutputSize,
+		SHA256:         job.SHA256,
+		OutputSettings: job.Settings,
+		Settings:       settings,
+		Options:        options,
+	}
+}
+
+// Append adds an entry to the end of the history
+func Append(entry Entry) error {
+	path, err := config.Path(historyFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Search returns the entries matching the filter, newest first
+func Search(filter Filter) ([]Entry, error) {
+	from, to, err := filter.dateRange()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := readAll()
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(filter.Name)
+	matches := []Entry{}
+	for _, entry := range entries {
+		if name != "" && !strings.Contains(strings.ToLower(filepath.Base(entry.Source)), name) &&
+			!strings.Contains(strings.ToLower(filepath.Base(entry.Output)), name) {
+			continue
+		}
+		if !from.IsZero() && entry.Finished.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !entry.Finished.Before(to) {
+			continue
+		}
+		if filter.Preset != "" && entry.Preset != filter.Preset {
+			continue
+		}
+		if filter.Status != "" && entry.Status != filter.Status {
+			continue
+		}
+		matches = append(matches, entry)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Finished.After(matches[j].Finished)
+	})
+	if filter.Limit > 0 && len(matches) > filter.Limit {
+		matches = matches[:filter.Limit]
+	}
+	return matches, nil
+}
+
+// Find returns the entry with the given ID
+func Find(id string) (*Entry, error) {
+	entries, err := readAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("기록을 찾을 수 없습니다: %s", id)
+}
+
+// dateRange parses the filter dates in local time. The end is exclusive, the day after To.
+func (f Filter) dateRange() (from, to time.Time, err error) {
+	if f.From != "" {
+		if from, err = time.ParseInLocation(dateLayout, f.From, time.Local); err != nil {
+			return from, to, fmt.Errorf("잘못된 날짜입니다: %s", f.From)
+		}
+	}
+	if f.To != "" {
+		if to, err = time.ParseInLocation(dateLayout, f.To, time.Local); err != nil {
+			return from, to, fmt.Errorf("잘못된 날짜입니다: %s", f.To)
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to, nil
+}
+
+// readAll reads every entry in append order, skipping lines that don't parse
+// (e.g. a line cut short by a crash)
+func readAll() ([]Entry, error) {
+	path, err := config.Path(historyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var entry Entry
+			if err := json.Unmarshal(line, &entry); err == nil {
+				entries = append(entries, entry)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,150 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"syncLauperVideoConverter/internal/config"
+	"syncLauperVideoConverter/internal/encoder"
+	"syncLauperVideoConverter/internal/fileinfo"
+	"syncLauperVideoConverter/internal/preset"
+)
+
+// useTempConfig points the config directory at a temporary one
+func useTempConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+}
+
+func TestSearch(t *testing.T) {
+	useTempConfig(t)
+
+	day := func(d, hour int) time.Time { return time.Date(2026, 3, d, hour, 0, 0, 0, time.Local) }
+	entries := []Entry{
+		{ID: "1", Finished: day(1, 10), Source: "/in/Intro.mov", Output: "/out/Intro.mkv", Preset: "A", Status: encoder.StatusCompleted},
+		{ID: "2", Finished: day(2, 23), Source: "/in/main.mov", Output: "/out/main.mkv", Preset: "B", Status: encoder.StatusError},
+		{ID: "3", Finished: day(3, 0), Source: "/in/outro.mov", Output: "/out/outro_final.mkv", Preset: "A", Status: encoder.StatusCompleted},
+	}
+	for _, entry := range entries {
+		if err := Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A line cut short by a crash is skipped
+	path, err := config.Path(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"id":"4","source":`)
+	file.Close()
+
+	tests := []struct {
+		name   string
+		filter Filter
+		ids    []string
+	}{
+		{"all newest first", Filter{}, []string{"3", "2", "1"}},
+		{"source name case-insensitive", Filter{Name: "INTRO"}, []string{"1"}},
+		{"output name", Filter{Name: "final"}, []string{"3"}},
+		{"from", Filter{From: "2026-03-02"}, []string{"3", "2"}},
+		{"to is inclusive", Filter{To: "2026-03-02"}, []string{"2", "1"}},
+		{"single day", Filter{From: "2026-03-02", To: "2026-03-02"}, []string{"2"}},
+		{"preset", Filter{Preset: "A"}, []string{"3", "1"}},
+		{"status", Filter{Status: encoder.StatusError}, []string{"2"}},
+		{"combined", Filter{Preset: "A", From: "2026-03-02"}, []string{"3"}},
+		{"limit", Filter{Limit: 2}, []string{"3", "2"}},
+		{"no match", Filter{Name: "missing"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Search(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.ids) {
+				t.Fatalf("got %d entries, want %v", len(got), tt.ids)
+			}
+			for i, id := range tt.ids {
+				if got[i].ID != id {
+					t.Errorf("entry %d = %s, want %s", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+
+	if _, err := Search(Filter{From: "03/01/2026"}); err == nil {
+		t.Error("bad date: expected an error")
+	}
+}
+
+func TestSearchEmptyHistory(t *testing.T) {
+	useTempConfig(t)
+
+	got, err := Search(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d entries, want none", len(got))
+	}
+}
+
+func TestFind(t *testing.T) {
+	useTempConfig(t)
+
+	if err := Append(Entry{ID: "42", Source: "/in/a.mov", Options: encoder.JobOptions{TrimStart: 1.5}}); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := Find("42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Source != "/in/a.mov" || entry.Options.TrimStart != 1.5 {
+		t.Errorf("found %+v", entry)
+	}
+	if _, err := Find("43"); err == nil {
+		t.Error("missing ID: expected an error")
+	}
+}
+
+func TestNewEntry(t *testing.T) {
+	ran := encoder.Settings{Encoder: "hevc_nvenc", Quality: 20}
+	job := &encoder.EncodingJob{
+		InputPath:       "/in/a.mov",
+		OutputPath:      filepath.Join("/out", "a.mkv"),
+		Preset:          &preset.Preset{Name: "A"},
+		FileInfo:        &fileinfo.FileInfo{Name: "a.mov"},
+		Status:          encoder.StatusCompleted,
+		EncoderSettings: &ran,
+		Options: encoder.JobOptions{
+			TrimStart: 2,
+			Settings:  &ran,
+			Preset:    "A",
+			OutputDir: "/out",
+		},
+	}
+
+	entry := NewEntry(job, ran)
+	if entry.Settings.Encoder != "hevc_nvenc" || entry.Settings.Quality != 20 {
+		t.Errorf("settings = %+v, want the given ones", entry.Settings)
+	}
+	if entry.Preset != "A" || entry.OutputDir != "/out" {
+		t.Errorf("preset %q, output dir %q", entry.Preset, entry.OutputDir)
+	}
+	if entry.Options.TrimStart != 2 {
+		t.Errorf("trim start = %v, want 2", entry.Options.TrimStart)
+	}
+	if entry.Options.Settings != nil || entry.Options.Preset != "" || entry.Options.OutputDir != "" {
+		t.Errorf("re-queue fields kept in options: %+v", entry.Options)
+	}
+}
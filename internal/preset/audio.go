@@ -0,0 +1,214 @@
+package preset
+
+import "fmt"
+
+// Audio modes
+const (
+	AudioModeAAC  = "aac"  // re-encode to AAC (default)
+	AudioModeCopy = "copy" // pass the source track through when the codec is compatible
+	AudioModeNone = "none" // remove audio
+)
+
+// Audio channel layouts
+const (
+	AudioChannelsStereo   = "stereo"
+	AudioChannels51       = "5.1"
+	AudioChannels71       = "7.1"
+	AudioChannelsDiscrete = "discrete" // keep every source channel without up/downmixing
+)
+
+// AudioOptions controls the audio track of the output
+type AudioOptions struct {
+	Mode     string `json:"mode"`     // AudioModeAAC, AudioModeCopy or AudioModeNone ("" = AAC)
+	Bitrate  int    `json:"bitrate"`  // AAC bitrate in kbps (0 = by channel layout)
+	Channels string `json:"channels"` // "stereo", "5.1", "7.1", "discrete" ("" = stereo)
+	Track    int    `json:"track"`    // source audio track index (0 = first)
+}
+
+// AudioTrack describes a source audio track
+type AudioTrack struct {
+	Codec    string
+	Channels int
+}
+
+// passthroughCodecs are source codecs that Matroska and the playback hardware accept as-is
+var passthroughCodecs = map[string]bool{
+	"aac":       true,
+	"ac3":       true,
+	"eac3":      true,
+	"mp3":       true,
+	"opus":      true,
+	"flac":      true,
+	"pcm_s16le": true,
+	"pcm_s24le": true,
+}
+
+// audioPlan is the resolved audio handling for one output
+type audioPlan struct {
+	mode     string // AudioModeAAC, AudioModeCopy or AudioModeNone
+	track    int    // source audio track index
+	channels int    // output channel count (0 = same as source)
+	layout   string // channel layout for generated silence
+	bitrate  int    // AAC bitrate in kbps
+}
+
+// EffectiveAudio returns the preset audio options with the batch override applied
+func (p *Preset) EffectiveAudio(override *AudioOptions) AudioOptions {
+	if override != nil {
+		return *override
+	}
+	return p.Audio
+}
+
+// resolveAudio decides how the audio of a source is written.
+// canCopy is false when the audio is filtered (black intro concat, loudness normalization).
+func resolveAudio(opts AudioOptions, sourceInfo *FileInfo, defaultBitrate int, canCopy bool) audioPlan {
+	plan := audioPlan{mode: opts.Mode, track: opts.Track}
+	if plan.mode == "" {
+		plan.mode = AudioModeAAC
+	}
+
+	// Known sources without audio get no audio track; unknown sources keep the old behavior
+	var track *AudioTrack
+	if sourceInfo != nil && sourceInfo.AudioTracks != nil {
+		if len(sourceInfo.AudioTracks) == 0 {
+			plan.mode = AudioModeNone
+			return plan
+		}
+		if plan.track < 0 || plan.track >= len(sourceInfo.AudioTracks) {
+			plan.track = 0
+		}
+		track = &sourceInfo.AudioTracks[plan.track]
+	}
+	if plan.mode == AudioModeNone {
+		return plan
+	}
+
+	if plan.mode == AudioModeCopy {
+		if canCopy && track != nil && passthroughCodecs[track.Codec] {
+			return plan
+		}
+		// Incompatible codec or filtered audio: re-encode, keeping the source channels
+		plan.mode = AudioModeAAC
+		opts.Channels = AudioChannelsDiscrete
+		opts.Bitrate = 0
+	}
+
+	sourceChannels := 0
+	if track != nil {
+		sourceChannels = track.Channels
+	}
+
+	switch opts.Channels {
+	case AudioChannels51:
+		plan.channels, plan.layout, plan.bitrate = 6, "5.1", 384
+	case AudioChannels71:
+		plan.channels, plan.layout, plan.bitrate = 8, "7.1", 512
+	case AudioChannelsDiscrete:
+		plan.layout = channelLayoutName(sourceChannels)
+		plan.bitrate = defaultBitrate
+		if sourceChannels > 2 {
+			plan.bitrate = 64 * sourceChannels
+		}
+	default:
+		plan.channels, plan.layout, plan.bitrate = 2, "stereo", defaultBitrate
+	}
+	if opts.Bitrate > 0 {
+		plan.bitrate = opts.Bitrate
+	}
+
+	return plan
+}
+
+// channelLayoutName returns the FFmpeg channel layout for a channel count
+func channelLayoutName(channels int) string {
+	switch channels {
+	case 1:
+		return "mono"
+	case 6:
+		return "5.1"
+	case 8:
+		return "7.1"
+	default:
+		return "stereo"
+	}
+}
+
+// mapArgs returns the -map arguments for the source audio track
+// (optional, so sources without that track still encode)
+func (a audioPlan) mapArgs(input int) []string {
+	if a.mode == AudioModeNone {
+		return nil
+	}
+	return []string{"-map", fmt.Sprintf("%d:a:%d?", input, a.track)}
+}
+
+// codecArgs returns the audio codec arguments
+func (a audioPlan) codecArgs() []string {
+	switch a.mode {
+	case AudioModeNone:
+		return []string{"-an"}
+	case AudioModeCopy:
+		return []string{"-c:a", "copy"}
+	}
+
+	args := []string{
+		"-c:a", "aac",
+		"-b:a", fmt.Sprintf("%dk", a.bitrate),
+	}
+	if a.channels > 0 {
+		args = append(args, "-ac", fmt.Sprintf("%d", a.channels))
+	}
+	return args
+}
+
+// audioFilters returns the filters for the source audio: offset correction, loudness normalization, then fades
+func (bc *buildContext) audioFilters() []string {
+	if bc.audio.mode != AudioModeAAC {
+		return nil
+	}
+
+	var filters []string
+	if bc.opts.AudioOffsetMs != 0 {
+		filters = append(filters, AudioOffsetFilters(bc.opts.AudioOffsetMs, bc.sourceDuration())...)
+	}
+	if bc.opts.Loudnorm != nil {
+		filters = append(filters, bc.opts.Loudnorm.filter())
+	}
+	filters = append(filters, bc.opts.Fades.audioFadeFilters(bc.sourceDuration())...)
+	return filters
+}
+
+// AudioOffsetFilters shifts the audio against the video by offsetMs (+ = audio later),
+// keeping the track as long as the source so loops stay aligned (duration 0 = unknown)
+func AudioOffsetFilters(offsetMs int, duration float64) []string {
+	if offsetMs > 0 {
+		filters := []string{fmt.Sprintf("adelay=delays=%d:all=1", offsetMs)}
+		if duration > 0 {
+			filters = append(filters, fmt.Sprintf("atrim=end=%.3f", duration))
+		}
+		return filters
+	}
+
+	filters := []string{
+		fmt.Sprintf("atrim=start=%.3f", float64(-offsetMs)/1000),
+		"asetpts=PTS-STARTPTS",
+	}
+	if duration > 0 {
+		filters = append(filters, fmt.Sprintf("apad=whole_dur=%.3f", duration))
+	}
+	return filters
+}
+
+// audioOffsetInputArgs returns the input options that shift a second, audio-only input
+// of the source, for copied audio that can't be filtered. start and end are the trim of the video input.
+func audioOffsetInputArgs(offsetMs int, start, end float64) []string {
+	if offsetMs > 0 {
+		return append(TrimArgs(start, end), "-itsoffset", fmt.Sprintf("%.3f", float64(offsetMs)/1000))
+	}
+	shift := float64(-offsetMs) / 1000
+	if end > 0 {
+		end += shift
+	}
+	return TrimArgs(start+shift, end)
+}
@@ -0,0 +1,27 @@
+package preset
+
+import "testing"
+
+func TestResolveAudioTrackList(t *testing.T) {
+	tests := []struct {
+		name   string
+		tracks []AudioTrack
+		opts   AudioOptions
+		mode   string
+	}{
+		{"unknown tracks keep AAC", nil, AudioOptions{}, AudioModeAAC},
+		{"unknown tracks can't be copied", nil, AudioOptions{Mode: AudioModeCopy}, AudioModeAAC},
+		{"no tracks means no audio", []AudioTrack{}, AudioOptions{}, AudioModeNone},
+		{"compatible track is copied", []AudioTrack{{Codec: "ac3", Channels: 6}}, AudioOptions{Mode: AudioModeCopy}, AudioModeCopy},
+		{"incompatible track is re-encoded", []AudioTrack{{Codec: "dts", Channels: 6}}, AudioOptions{Mode: AudioModeCopy}, AudioModeAAC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := resolveAudio(tt.opts, &FileInfo{AudioTracks: tt.tracks}, 160, true)
+			if plan.mode != tt.mode {
+				t.Errorf("mode = %q, want %q", plan.mode, tt.mode)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package preset
+
+import "fmt"
+
+// colorTags are the four color properties written to the output stream (FFmpeg names)
+type colorTags struct {
+	primaries  string
+	transfer   string
+	space      string
+	colorRange string
+}
+
+// bt709Limited is the standard HD delivery color space
+var bt709Limited = colorTags{"bt709", "bt709", "bt709", "tv"}
+
+// sourceColorTags returns the source color properties, filling unknown values the way
+// players guess them: BT.2020 for HDR, BT.709 for HD and larger, SMPTE 170M (BT.601)
+// for SD, limited range
+func sourceColorTags(sourceInfo *FileInfo) colorTags {
+	guess := bt709Limited
+	if sourceInfo == nil {
+		return guess
+	}
+	switch {
+	case isHDRTransfer(sourceInfo.ColorTransfer):
+		guess = colorTags{"bt2020", sourceInfo.ColorTransfer, "bt2020nc", "tv"}
+	case sourceInfo.Height > 0 && sourceInfo.Height < 720:
+		guess = colorTags{"smpte170m", "smpte170m", "smpte170m", "tv"}
+	}
+
+	tags := colorTags{
+		primaries:  sourceInfo.ColorPrimaries,
+		transfer:   sourceInfo.ColorTransfer,
+		space:      sourceInfo.ColorSpace,
+		colorRange: sourceInfo.ColorRange,
+	}
+	if tags.primaries == "" {
+		tags.primaries = guess.primaries
+	}
+	if tags.transfer == "" {
+		tags.transfer = guess.transfer
+	}
+	if tags.space == "" {
+		tags.space = guess.space
+	}
+	if tags.colorRange == "" {
+		tags.colorRange = guess.colorRange
+	}
+	return tags
+}
+
+// applyTo writes the tags into the encoding settings
+func (t colorTags) applyTo(settings *EncodingSettings) {
+	settings.ColorPrimaries = t.primaries
+	settings.ColorTransfer = t.transfer
+	settings.ColorSpace = t.space
+	settings.ColorRange = t.colorRange
+}
+
+// zscaleNames maps FFmpeg color names to the zscale option values where they differ
+var zscaleNames = map[string]string{
+	"smpte170m": "170m",
+	"bt470bg":   "470bg",
+	"smpte240m": "240m",
+	"bt2020nc":  "2020_ncl",
+	"bt2020c":   "2020_cl",
+	"bt2020-10": "2020_10",
+	"bt2020-12": "2020_12",
+}
+
+// zscaleName returns the zscale option value for an FFmpeg color name
+func zscaleName(name string) string {
+	if z, ok := zscaleNames[name]; ok {
+		return z
+	}
+	return name
+}
+
+// convertColorFilters converts SDR frames in system memory from the source
+// color space to the target tags
+func convertColorFilters(from colorTags, to colorTags, bitDepth int) []string {
+	// SMPTE 170M transfer is identical to BT.709; zscale names it "601"
+	transfer := from.transfer
+	if transfer == "smpte170m" {
+		transfer = "601"
+	}
+	return []string{
+		fmt.Sprintf("zscale=pin=%s:tin=%s:min=%s:rin=%s:p=%s:t=%s:m=%s:r=%s",
+			zscaleName(from.primaries), zscaleName(transfer), zscaleName(from.space), from.colorRange,
+			zscaleName(to.primaries), zscaleName(to.transfer), zscaleName(to.space), to.colorRange),
+		"format=" + planarFormat(bitDepth),
+	}
+}
+
+// planarFormat returns the planar YUV format zscale outputs for the bit depth.
+// zscale can't output the semi-planar formats hardware encoders take, so -pix_fmt converts.
+func planarFormat(bitDepth int) string {
+	if bitDepth == 10 {
+		return "yuv420p10le"
+	}
+	return "yuv420p"
+}
+
+// colorArgs returns the output color tags (-color_primaries, -color_trc, -colorspace, -color_range)
+func colorArgs(settings EncodingSettings) []string {
+	var args []string
+	if settings.ColorPrimaries != "" {
+		args = append(args, "-color_primaries", settings.ColorPrimaries)
+	}
+	if settings.ColorTransfer != "" {
+		args = append(args, "-color_trc", settings.ColorTransfer)
+	}
+	if settings.ColorSpace != "" {
+		args = append(args, "-colorspace", settings.ColorSpace)
+	}
+	if settings.ColorRange != "" {
+		args = append(args, "-color_range", settings.ColorRange)
+	}
+	return args
+}
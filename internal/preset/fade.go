@@ -0,0 +1,35 @@
+package preset
+
+import "fmt"
+
+// FadeOptions are the fade durations in seconds applied to the source segment (0 = no fade).
+// Fades out need a known source duration.
+type FadeOptions struct {
+	VideoIn  float64 `json:"videoIn"`
+	VideoOut float64 `json:"videoOut"`
+	AudioIn  float64 `json:"audioIn"`
+	AudioOut float64 `json:"audioOut"`
+}
+
+// videoFadeFilters returns the fade filters for source frames in system memory
+func (f FadeOptions) videoFadeFilters(duration float64) []string {
+	return fadeFilters("fade", f.VideoIn, f.VideoOut, duration)
+}
+
+// audioFadeFilters returns the afade filters for the source audio
+func (f FadeOptions) audioFadeFilters(duration float64) []string {
+	return fadeFilters("afade", f.AudioIn, f.AudioOut, duration)
+}
+
+// fadeFilters builds fade-in and fade-out filters; the fade out is skipped when the
+// duration is unknown or shorter than the fade
+func fadeFilters(filter string, in, out, duration float64) []string {
+	var filters []string
+	if in > 0 {
+		filters = append(filters, fmt.Sprintf("%s=t=in:st=0:d=%.3f", filter, in))
+	}
+	if out > 0 && duration > out {
+		filters = append(filters, fmt.Sprintf("%s=t=out:st=%.3f:d=%.3f", filter, duration-out, out))
+	}
+	return filters
+}
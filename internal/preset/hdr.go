@@ -0,0 +1,62 @@
+package preset
+
+import "fmt"
+
+// HDR handling modes
+const (
+	HDRModeToneMap  = "tonemap"  // tone map to SDR and tag BT.709
+	HDRModePreserve = "preserve" // keep HDR10/HLG with 10-bit output and HDR metadata
+)
+
+// ToneMapAlgorithms lists the supported tonemap filter algorithms
+var ToneMapAlgorithms = []string{"hable", "mobius", "reinhard"}
+
+// defaultToneMap is used when neither the preset nor the batch selects an algorithm
+const defaultToneMap = "hable"
+
+// isHDRTransfer reports whether a transfer function is HDR (PQ or HLG)
+func isHDRTransfer(transfer string) bool {
+	return transfer == "smpte2084" || transfer == "arib-std-b67"
+}
+
+// EffectiveHDRMode returns how an HDR source is handled ("" for SDR sources).
+// override forces a mode (HDRModeToneMap or HDRModePreserve, "" = use the preset).
+func (p *Preset) EffectiveHDRMode(sourceInfo *FileInfo, override string) string {
+	if sourceInfo == nil || !isHDRTransfer(sourceInfo.ColorTransfer) {
+		return ""
+	}
+	if override == HDRModeToneMap || override == HDRModePreserve {
+		return override
+	}
+	if p.PreserveHDR {
+		return HDRModePreserve
+	}
+	return HDRModeToneMap
+}
+
+// effectiveToneMap returns the tonemap algorithm, preferring the batch override
+func (p *Preset) effectiveToneMap(override string) string {
+	for _, algorithm := range []string{override, p.ToneMap} {
+		for _, supported := range ToneMapAlgorithms {
+			if algorithm == supported {
+				return algorithm
+			}
+		}
+	}
+	return defaultToneMap
+}
+
+// toneMapFilters converts HDR frames in system memory to BT.709 SDR.
+// The source properties are set explicitly because decoded frames are not always tagged.
+func toneMapFilters(sourceInfo *FileInfo, algorithm string, bitDepth int) []string {
+	src := sourceColorTags(sourceInfo)
+	return []string{
+		fmt.Sprintf("zscale=tin=%s:pin=%s:min=%s:rin=%s:t=linear:npl=100",
+			zscaleName(src.transfer), zscaleName(src.primaries), zscaleName(src.space), src.colorRange),
+		"format=gbrpf32le",
+		"zscale=p=bt709",
+		fmt.Sprintf("tonemap=tonemap=%s:desat=0", algorithm),
+		"zscale=t=bt709:m=bt709:r=tv",
+		"format=" + planarFormat(bitDepth),
+	}
+}
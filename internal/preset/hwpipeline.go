@@ -0,0 +1,99 @@
+package preset
+
+import "fmt"
+
+// hwPipeline describes hardware decoding and filtering for an encoder
+type hwPipeline struct {
+	hwaccel      string // -hwaccel value
+	hwaccelDev   string // -hwaccel_device value ("" = none)
+	outputFormat string // -hwaccel_output_format ("" = frames are downloaded to system memory)
+	codecs       map[string]bool
+}
+
+// hwPipelines maps encoder IDs to the matching hardware decoder
+var hwPipelines = map[string]hwPipeline{
+	"hevc_vaapi": {
+		hwaccel:      "vaapi",
+		hwaccelDev:   "hw", // device created by GetPreInputArgs
+		outputFormat: "vaapi",
+		codecs:       map[string]bool{"h264": true, "hevc": true, "vp9": true, "av1": true, "mpeg2video": true},
+	},
+	"hevc_qsv": {
+		hwaccel:      "qsv",
+		hwaccelDev:   "hw", // device created by GetPreInputArgs
+		outputFormat: "qsv",
+		codecs:       map[string]bool{"h264": true, "hevc": true, "vp9": true, "av1": true, "mpeg2video": true},
+	},
+	"hevc_nvenc": {
+		hwaccel:      "cuda",
+		outputFormat: "cuda",
+		codecs:       map[string]bool{"h264": true, "hevc": true, "vp9": true, "av1": true, "mpeg2video": true},
+	},
+	"hevc_videotoolbox": {
+		// VideoToolbox decodes to system memory; filtering stays on the CPU
+		hwaccel: "videotoolbox",
+		codecs:  map[string]bool{"h264": true, "hevc": true, "prores": true},
+	},
+}
+
+// SupportsHWDecode reports whether the source codec can be decoded on the hardware
+// that belongs to the given encoder
+func SupportsHWDecode(encoderID string, sourceCodec string) bool {
+	pipeline, ok := hwPipelines[encoderID]
+	return ok && pipeline.codecs[sourceCodec]
+}
+
+// hwDecodeArgs returns the -hwaccel arguments that must appear directly before the source -i
+func (hw *hwPipeline) hwDecodeArgs() []string {
+	args := []string{"-hwaccel", hw.hwaccel}
+	if hw.hwaccelDev != "" {
+		args = append(args, "-hwaccel_device", hw.hwaccelDev)
+	}
+	if hw.outputFormat != "" {
+		args = append(args, "-hwaccel_output_format", hw.outputFormat)
+	}
+	return args
+}
+
+// hwFrames reports whether decoded frames stay in GPU memory
+func (hw *hwPipeline) hwFrames() bool {
+	return hw != nil && hw.outputFormat != ""
+}
+
+// hwVideoFilters returns the GPU deinterlace/scale filters for frames kept in GPU memory.
+// Every chain ends in pixFmt (nv12 or p010le) so frames can be downloaded or encoded directly.
+func hwVideoFilters(hw *hwPipeline, deinterlace bool, width, height int, pixFmt string) []string {
+	var filters []string
+
+	switch hw.hwaccel {
+	case "vaapi":
+		if deinterlace {
+			filters = append(filters, "deinterlace_vaapi=auto=1")
+		}
+		if width > 0 && height > 0 {
+			filters = append(filters, fmt.Sprintf("scale_vaapi=w=%d:h=%d:format=%s", width, height, pixFmt))
+		} else {
+			filters = append(filters, "scale_vaapi=format="+pixFmt)
+		}
+	case "qsv":
+		vpp := "vpp_qsv=format=" + pixFmt
+		if deinterlace {
+			vpp += ":deinterlace=advanced"
+		}
+		if width > 0 && height > 0 {
+			vpp += fmt.Sprintf(":w=%d:h=%d", width, height)
+		}
+		filters = append(filters, vpp)
+	case "cuda":
+		if deinterlace {
+			filters = append(filters, "yadif_cuda=mode=0:parity=-1:deint=1")
+		}
+		if width > 0 && height > 0 {
+			filters = append(filters, fmt.Sprintf("scale_cuda=w=%d:h=%d:format=%s", width, height, pixFmt))
+		} else {
+			filters = append(filters, "scale_cuda=format="+pixFmt)
+		}
+	}
+
+	return filters
+}
@@ -0,0 +1,54 @@
+package preset
+
+import (
+	"fmt"
+	"strings"
+)
+
+// loopCrossfade returns the loop crossfade in seconds, or 0 when it is off or the
+// source is too short (or of unknown length) to crossfade its tail into its head
+func (bc *buildContext) loopCrossfade() float64 {
+	fade := bc.opts.LoopCrossfade
+	if fade <= 0 || bc.sourceDuration() <= 2*fade {
+		return 0
+	}
+	return fade
+}
+
+// loopGraph builds a filter graph that turns the source into a seamless loop: the last
+// fade seconds are crossfaded into the first fade seconds and placed at the end, so the
+// output (fade seconds shorter than the source) flows from its last frame into its first.
+// The head is held in memory until the tail arrives.
+func (p *Preset) loopGraph(bc buildContext, fade float64, audioInput int) string {
+	cut := bc.sourceDuration() - fade
+
+	// xfade needs constant framerate input
+	filters := p.videoFilters(bc)
+	if bc.fps > 0 {
+		filters = append(filters, "fps="+frameRateArg(bc.fps))
+	}
+	filters = append(filters, "split=3")
+	graph := "[0:v]" + strings.Join(filters, ",") + fmt.Sprintf(
+		"[lbody][ltail][lhead];"+
+			"[lbody]trim=start=%[1]f:end=%[2]f,setpts=PTS-STARTPTS[body];"+
+			"[ltail]trim=start=%[2]f,setpts=PTS-STARTPTS[tail];"+
+			"[lhead]trim=end=%[1]f,setpts=PTS-STARTPTS[head];"+
+			"[tail][head]xfade=transition=fade:duration=%[1]f:offset=0[seam];"+
+			"[body][seam]concat=n=2:v=1:a=0[v]",
+		fade, cut)
+
+	if bc.audio.mode == AudioModeNone {
+		return graph
+	}
+
+	audioFilters := append(bc.audioFilters(), "asplit=3")
+	graph += fmt.Sprintf(";[%d:a:%d]", audioInput, bc.audio.track) + strings.Join(audioFilters, ",") + fmt.Sprintf(
+		"[labody][latail][lahead];"+
+			"[labody]atrim=start=%[1]f:end=%[2]f,asetpts=PTS-STARTPTS[abody];"+
+			"[latail]atrim=start=%[2]f,asetpts=PTS-STARTPTS[atail];"+
+			"[lahead]atrim=end=%[1]f,asetpts=PTS-STARTPTS[ahead];"+
+			"[atail][ahead]acrossfade=d=%[1]f[aseam];"+
+			"[abody][aseam]concat=n=2:v=0:a=1[a]",
+		fade, cut)
+	return graph
+}
@@ -0,0 +1,27 @@
+package preset
+
+import "fmt"
+
+// Loudnorm contains the EBU R128 target and the first-pass measurement of a source
+// for two-pass loudnorm
+type Loudnorm struct {
+	TargetI   float64 // integrated loudness target in LUFS, e.g., -23 or -16
+	TargetTP  float64 // true peak ceiling in dBTP
+	TargetLRA float64 // loudness range target in LU
+
+	MeasuredI      float64 // measured integrated loudness in LUFS
+	MeasuredTP     float64 // measured true peak in dBTP
+	MeasuredLRA    float64 // measured loudness range in LU
+	MeasuredThresh float64 // measured gating threshold in LUFS
+	Offset         float64 // target offset gain reported by the first pass
+}
+
+// filter returns the second-pass loudnorm filter. loudnorm upsamples to 192kHz,
+// so the output is resampled back to 48kHz.
+func (l *Loudnorm) filter() string {
+	return fmt.Sprintf(
+		"loudnorm=I=%.1f:TP=%.1f:LRA=%.1f:measured_I=%.2f:measured_TP=%.2f:measured_LRA=%.2f:measured_thresh=%.2f:offset=%.2f:linear=true,aresample=48000",
+		l.TargetI, l.TargetTP, l.TargetLRA,
+		l.MeasuredI, l.MeasuredTP, l.MeasuredLRA, l.MeasuredThresh, l.Offset,
+	)
+}
@@ -0,0 +1,47 @@
+package preset
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Overlay burns identification text into the picture (calibration variant):
+// running timecode and frame number at the top, filename at the bottom and the
+// screen label in the center
+type Overlay struct {
+	Filename string // source file name
+	Label    string // screen label ("" = none)
+	FontFile string // font for drawtext ("" = fontconfig default)
+}
+
+// overlayBox is the drawtext styling shared by all overlay lines
+const overlayBox = "fontcolor=white:box=1:boxcolor=black@0.6:boxborderw=10"
+
+// filters returns the drawtext filters for frames in system memory
+func (o *Overlay) filters(fps float64) []string {
+	font := ""
+	if o.FontFile != "" {
+		font = "fontfile=" + FilterEscape(o.FontFile) + ":"
+	}
+
+	filters := []string{
+		fmt.Sprintf("drawtext=%stimecode=%s:rate=%s:fontsize=h/16:x=h/30:y=h/30:%s",
+			font, FilterEscape("00:00:00:00"), frameRateArg(fps), overlayBox),
+		fmt.Sprintf("drawtext=%stext=%s:fontsize=h/20:x=h/30:y=h/30+h/10:%s",
+			font, FilterEscape("FRAME %{n}"), overlayBox),
+		fmt.Sprintf("drawtext=%sexpansion=none:text=%s:fontsize=h/24:x=h/30:y=h-th-h/30:%s",
+			font, FilterEscape(o.Filename), overlayBox),
+	}
+	if o.Label != "" {
+		filters = append(filters, fmt.Sprintf("drawtext=%sexpansion=none:text=%s:fontsize=h/6:x=(w-tw)/2:y=(h-th)/2:%s",
+			font, FilterEscape(o.Label), overlayBox))
+	}
+	return filters
+}
+
+// FilterEscape escapes a literal option value (text, path or expression) for use
+// inside a filter graph: once for the filter option syntax, then again for the graph syntax
+func FilterEscape(s string) string {
+	option := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`).Replace(s)
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`).Replace(option)
+}
@@ -3,6 +3,7 @@ package preset
 import (
 	"fmt"
 	"math"
+	"strings"
 )
 
 // FileInfo represents source file information (used for dynamic preset calculation)
@@ -10,21 +11,36 @@ type FileInfo struct {
 	Width     int
 	Height    int
 	Framerate float64
+	Duration  float64 // seconds (0 = unknown)
+	Codec     string  // e.g., "h264", "hevc" (used for hardware decode)
+	BitDepth  int     // luma bit depth (0 = unknown)
+
+	// Color properties (FFmpeg names, "" = unknown)
+	ColorPrimaries string
+	ColorTransfer  string
+	ColorSpace     string
+	ColorRange     string
+	MasterDisplay  string // HDR10 mastering display (x265 syntax)
+	MaxCLL         int
+	MaxFALL        int
+
+	AudioTracks []AudioTrack // source audio tracks (nil = unknown)
 }
 
 // GetAllPresets returns all available SyncLauper presets
 func GetAllPresets() []Preset {
 	return []Preset{
 		{
-			Name:         "원본 설정 유지",
-			Resolution:   "source",
-			Framerate:    "source",
-			Width:        0,
-			Height:       0,
-			Level:        "auto",
-			FPS:          0,
-			UseSourceFPS: true,
-			UseSourceRes: true,
+			Name:              "원본 설정 유지",
+			Resolution:        "source",
+			Framerate:         "source",
+			Width:             0,
+			Height:            0,
+			Level:             "auto",
+			FPS:               0,
+			UseSourceFPS:      true,
+			UseSourceRes:      true,
+			UseSourceBitDepth: true,
 		},
 		{
 			Name:       "HEVC 4K|60p",
@@ -34,6 +50,8 @@ func GetAllPresets() []Preset {
 			Height:     2160,
 			Level:      "5.1",
 			FPS:        60,
+			MaxBitrate: 35000,
+			ForceBT709: true,
 		},
 		{
 			Name:       "HEVC 4K|30p",
@@ -43,6 +61,8 @@ func GetAllPresets() []Preset {
 			Height:     2160,
 			Level:      "5.0",
 			FPS:        30,
+			MaxBitrate: 25000,
+			ForceBT709: true,
 		},
 		{
 			Name:       "HEVC 4K|29.97p",
@@ -52,6 +72,8 @@ func GetAllPresets() []Preset {
 			Height:     2160,
 			Level:      "5.0",
 			FPS:        29.97,
+			MaxBitrate: 25000,
+			ForceBT709: true,
 		},
 		{
 			Name:       "HEVC 4K|24p",
@@ -61,6 +83,8 @@ func GetAllPresets() []Preset {
 			Height:     2160,
 			Level:      "5.0",
 			FPS:        24,
+			MaxBitrate: 25000,
+			ForceBT709: true,
 		},
 		{
 			Name:       "HEVC 4K|23.976p",
@@ -70,6 +94,8 @@ func GetAllPresets() []Preset {
 			Height:     2160,
 			Level:      "5.0",
 			FPS:        23.976,
+			MaxBitrate: 25000,
+			ForceBT709: true,
 		},
 		{
 			Name:       "HEVC 1080p|60p",
@@ -79,6 +105,8 @@ func GetAllPresets() []Preset {
 			Height:     1080,
 			Level:      "4.1",
 			FPS:        60,
+			MaxBitrate: 15000,
+			ForceBT709: true,
 		},
 		{
 			Name:       "HEVC 1080p|30p",
@@ -88,6 +116,8 @@ func GetAllPresets() []Preset {
 			Height:     1080,
 			Level:      "4.1",
 			FPS:        30,
+			MaxBitrate: 10000,
+			ForceBT709: true,
 		},
 		{
 			Name:       "HEVC 1080p|29.97p",
@@ -97,6 +127,8 @@ func GetAllPresets() []Preset {
 			Height:     1080,
 			Level:      "4.1",
 			FPS:        29.97,
+			MaxBitrate: 10000,
+			ForceBT709: true,
 		},
 		{
 			Name:       "HEVC 1080p|24p",
@@ -106,6 +138,8 @@ func GetAllPresets() []Preset {
 			Height:     1080,
 			Level:      "4.1",
 			FPS:        24,
+			MaxBitrate: 10000,
+			ForceBT709: true,
 		},
 		{
 			Name:       "HEVC 1080p|23.976p",
@@ -115,6 +149,8 @@ func GetAllPresets() []Preset {
 			Height:     1080,
 			Level:      "4.1",
 			FPS:        23.976,
+			MaxBitrate: 10000,
+			ForceBT709: true,
 		},
 	}
 }
@@ -142,36 +178,178 @@ func DetermineLevel(width, height int, fps float64) string {
 	return "4.1"
 }
 
-// ToFFmpegArgs converts a preset to FFmpeg arguments
-func (p *Preset) ToFFmpegArgs(inputPath, outputPath string, sourceInfo *FileInfo) []string {
-	return p.ToFFmpegArgsWithEncoder(inputPath, outputPath, sourceInfo, "libx265", 0, 0)
+// DetermineVBV returns player-safe VBV max bitrate and buffer size (kbps) for an H.265 level
+func DetermineVBV(level string) (maxBitrate, bufSize int) {
+	switch level {
+	case "5.1":
+		maxBitrate = 35000
+	case "5.0":
+		maxBitrate = 25000
+	case "4.1":
+		maxBitrate = 15000
+	default:
+		return 0, 0
+	}
+	return maxBitrate, LevelCPBSize(level)
 }
 
-// ToFFmpegArgsWithEncoder converts a preset to FFmpeg arguments with specified encoder
-func (p *Preset) ToFFmpegArgsWithEncoder(inputPath, outputPath string, sourceInfo *FileInfo, encoderID string, quality int, blackIntroDuration int) []string {
-	settings := DefaultSettings()
-	if quality > 0 {
-		settings.Quality = quality
+// LevelCPBSize returns the H.265 Main tier maximum CPB size (kbit) of a level (0 = unknown)
+func LevelCPBSize(level string) int {
+	switch level {
+	case "4.0":
+		return 12000
+	case "4.1":
+		return 20000
+	case "5.0":
+		return 25000
+	case "5.1":
+		return 40000
+	case "5.2", "6.0":
+		return 60000
+	case "6.1":
+		return 120000
+	case "6.2":
+		return 240000
+	default:
+		return 0
 	}
+}
 
-	// Determine effective values for source-based preset
-	effectiveWidth := p.Width
-	effectiveHeight := p.Height
-	effectiveFPS := p.FPS
-	effectiveLevel := p.Level
+// EffectiveValues resolves resolution, framerate and level for source-based presets
+func (p *Preset) EffectiveValues(sourceInfo *FileInfo) (width, height int, fps float64, level string) {
+	width, height, fps, level = p.Width, p.Height, p.FPS, p.Level
 
 	if p.UseSourceRes && sourceInfo != nil {
-		effectiveWidth = sourceInfo.Width
-		effectiveHeight = sourceInfo.Height
+		width = sourceInfo.Width
+		height = sourceInfo.Height
 	}
 
 	if p.UseSourceFPS && sourceInfo != nil {
-		effectiveFPS = sourceInfo.Framerate
+		fps = sourceInfo.Framerate
 	}
 
 	// Calculate level for "auto" or source-based preset
-	if effectiveLevel == "auto" && sourceInfo != nil {
-		effectiveLevel = DetermineLevel(effectiveWidth, effectiveHeight, effectiveFPS)
+	if level == "auto" && sourceInfo != nil {
+		level = DetermineLevel(width, height, fps)
+	}
+
+	return width, height, fps, level
+}
+
+// EffectiveBitDepth returns the output bit depth (8 or 10) for the given source.
+// override forces a bit depth (0 = use the preset).
+func (p *Preset) EffectiveBitDepth(sourceInfo *FileInfo, override int) int {
+	if override == 8 || override == 10 {
+		return override
+	}
+	if p.UseSourceBitDepth && sourceInfo != nil && sourceInfo.BitDepth >= 10 {
+		return 10
+	}
+	if p.BitDepth == 10 {
+		return 10
+	}
+	return 8
+}
+
+// PixelFormat returns the input pixel format an encoder expects for the given bit depth
+func PixelFormat(encoderID string, bitDepth int) string {
+	if encoderID == "libx265" {
+		if bitDepth == 10 {
+			return "yuv420p10le"
+		}
+		return "yuv420p"
+	}
+	if bitDepth == 10 {
+		return "p010le"
+	}
+	return "nv12"
+}
+
+// VBVFor returns the VBV max bitrate and buffer size (kbps) used when encoding the given source
+func (p *Preset) VBVFor(sourceInfo *FileInfo) (maxBitrate, bufSize int) {
+	_, _, _, level := p.EffectiveValues(sourceInfo)
+	if p.MaxBitrate > 0 {
+		// Without an explicit buffer, the level's CPB size is the largest buffer players must handle
+		bufSize = p.BufSize
+		if bufSize <= 0 {
+			bufSize = LevelCPBSize(level)
+		}
+		if bufSize <= 0 {
+			bufSize = p.MaxBitrate
+		}
+		return p.MaxBitrate, bufSize
+	}
+	return DetermineVBV(level)
+}
+
+// ToFFmpegArgs converts a preset to FFmpeg arguments
+func (p *Preset) ToFFmpegArgs(inputPath, outputPath string, sourceInfo *FileInfo) []string {
+	return p.ToFFmpegArgsWithOptions(inputPath, outputPath, sourceInfo, EncodeOptions{EncoderID: "libx265"})
+}
+
+// buildContext holds the resolved values shared by the argument builders
+type buildContext struct {
+	inputPath  string
+	outputPath string
+	settings   EncodingSettings
+	opts       EncodeOptions
+	width      int
+	height     int
+	fps        float64
+	level      string
+	keyint     int
+	bitDepth   int         // output bit depth (8 or 10)
+	pixFmt     string      // output pixel format for the encoder
+	toneMap    string      // tonemap algorithm for HDR sources ("" = no tone mapping)
+	convert709 bool        // convert SDR source colors to BT.709 limited range
+	source     *FileInfo   // source properties (may be nil)
+	audio      audioPlan   // resolved audio handling
+	hw         *hwPipeline // hardware decode pipeline (nil = software decode)
+}
+
+// ToFFmpegArgsWithOptions converts a preset to FFmpeg arguments with the given encoder and batch options
+func (p *Preset) ToFFmpegArgsWithOptions(inputPath, outputPath string, sourceInfo *FileInfo, opts EncodeOptions) []string {
+	settings := DefaultSettings()
+	if opts.Quality > 0 {
+		settings.Quality = opts.Quality
+	}
+
+	// Determine effective values for source-based preset
+	effectiveWidth, effectiveHeight, effectiveFPS, effectiveLevel := p.EffectiveValues(sourceInfo)
+
+	// VBV caps keep bitrate peaks within what the player hardware decodes smoothly
+	settings.MaxBitrate, settings.BufSize = p.VBVFor(sourceInfo)
+
+	// 10-bit output uses the main10 profile to avoid banding in gradients
+	bitDepth := p.EffectiveBitDepth(sourceInfo, opts.BitDepth)
+
+	// Outputs always carry explicit color tags so every screen renders the batch alike.
+	// HDR sources are either tone mapped to BT.709 or kept as HDR, which needs 10-bit.
+	toneMap := ""
+	convert709 := false
+	switch p.EffectiveHDRMode(sourceInfo, opts.HDRMode) {
+	case HDRModeToneMap:
+		toneMap = p.effectiveToneMap(opts.ToneMap)
+		bt709Limited.applyTo(&settings)
+	case HDRModePreserve:
+		bitDepth = 10
+		sourceColorTags(sourceInfo).applyTo(&settings)
+		settings.MasterDisplay = sourceInfo.MasterDisplay
+		if sourceInfo.MaxCLL > 0 {
+			settings.MaxCLL = fmt.Sprintf("%d,%d", sourceInfo.MaxCLL, sourceInfo.MaxFALL)
+		}
+	default:
+		src := sourceColorTags(sourceInfo)
+		if p.ForceBT709 {
+			bt709Limited.applyTo(&settings)
+			convert709 = src != bt709Limited
+		} else {
+			src.applyTo(&settings)
+		}
+	}
+
+	if bitDepth == 10 {
+		settings.EncoderProfile = "main10"
 	}
 
 	// Build keyint for GOP settings
@@ -180,28 +358,130 @@ func (p *Preset) ToFFmpegArgsWithEncoder(inputPath, outputPath string, sourceInf
 		keyint = 30 // fallback
 	}
 
-	if blackIntroDuration > 0 {
-		return p.buildArgsWithBlackIntro(inputPath, outputPath, settings, encoderID, effectiveWidth, effectiveHeight, effectiveFPS, effectiveLevel, keyint, blackIntroDuration)
+	bc := buildContext{
+		inputPath:  inputPath,
+		outputPath: outputPath,
+		settings:   settings,
+		opts:       opts,
+		width:      effectiveWidth,
+		height:     effectiveHeight,
+		fps:        effectiveFPS,
+		level:      effectiveLevel,
+		keyint:     keyint,
+		bitDepth:   bitDepth,
+		pixFmt:     PixelFormat(opts.EncoderID, bitDepth),
+		toneMap:    toneMap,
+		convert709: convert709,
+		source:     sourceInfo,
+	}
+
+	// Audio can only be copied when it isn't filtered (pre/post-roll concat, loop crossfade, loudness normalization, fades).
+	// A copied track is shifted with a second input instead of filters.
+	canCopy := !bc.hasRolls() && bc.loopCrossfade() == 0 && opts.Loudnorm == nil && opts.Fades.AudioIn <= 0 && opts.Fades.AudioOut <= 0
+	bc.audio = resolveAudio(p.EffectiveAudio(opts.Audio), sourceInfo, settings.AudioBitrate, canCopy)
+
+	// Decode (and filter) on the GPU when the encoder's hardware supports the source codec
+	if opts.HWDecode && sourceInfo != nil && SupportsHWDecode(opts.EncoderID, sourceInfo.Codec) {
+		pipeline := hwPipelines[opts.EncoderID]
+		if toneMap != "" || convert709 || opts.Fades.VideoIn > 0 || opts.Fades.VideoOut > 0 || opts.Overlay != nil || opts.LoopCrossfade > 0 {
+			// Tone mapping, color conversion, fades, overlays and loop crossfades run on the CPU, so decoded frames are downloaded to system memory
+			pipeline.outputFormat = ""
+		}
+		bc.hw = &pipeline
+	}
+
+	if bc.hasRolls() {
+		return p.buildArgsWithRolls(bc)
+	}
+
+	return p.buildStandardArgs(bc)
+}
+
+// hasRolls reports whether a pre-roll or post-roll is added around the source
+func (bc *buildContext) hasRolls() bool {
+	return bc.opts.PreRoll.active(bc.fps) || bc.opts.PostRoll.active(bc.fps)
+}
+
+// sourceDuration returns the duration in seconds of the source after trimming (0 = unknown)
+func (bc *buildContext) sourceDuration() float64 {
+	if bc.source == nil {
+		return 0
+	}
+	return TrimmedDuration(bc.source.Duration, bc.opts.TrimStart, bc.opts.TrimEnd)
+}
+
+// TrimmedDuration returns the length of a source cut at the start and end points
+// in seconds (end 0 = the end of the source)
+func TrimmedDuration(duration, start, end float64) float64 {
+	if end > 0 && end < duration {
+		duration = end
 	}
+	return math.Max(0, duration-start)
+}
 
-	return p.buildStandardArgs(inputPath, outputPath, settings, encoderID, effectiveWidth, effectiveHeight, effectiveFPS, effectiveLevel, keyint)
+// TrimArgs returns the input options for in and out points (end 0 = the end).
+// Seeking before -i is frame accurate when re-encoding.
+func TrimArgs(start, end float64) []string {
+	var args []string
+	if start > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%.3f", start))
+	}
+	if end > start {
+		args = append(args, "-t", fmt.Sprintf("%.3f", end-start))
+	}
+	return args
 }
 
-// buildStandardArgs builds FFmpeg args without black intro (original logic)
-func (p *Preset) buildStandardArgs(inputPath, outputPath string, settings EncodingSettings, encoderID string, effectiveWidth, effectiveHeight int, effectiveFPS float64, effectiveLevel string, keyint int) []string {
+// buildStandardArgs builds FFmpeg args without pre/post-roll (original logic)
+func (p *Preset) buildStandardArgs(bc buildContext) []string {
 	args := []string{}
 
 	// Add pre-input args for hardware encoders (must come before -i)
-	args = append(args, getPreInputArgs(encoderID)...)
+	args = append(args, GetPreInputArgs(bc.opts.EncoderID, bc.opts.RenderDevice)...)
 
-	args = append(args, "-i", inputPath)
+	// Hardware decode args apply to the source input only
+	if bc.hw != nil {
+		args = append(args, bc.hw.hwDecodeArgs()...)
+	}
+	if bc.opts.InputFormat != "" {
+		args = append(args, "-f", bc.opts.InputFormat)
+	}
+	args = append(args, TrimArgs(bc.opts.TrimStart, bc.opts.TrimEnd)...)
+	args = append(args, "-i", bc.inputPath)
+
+	// Copied audio can't be filtered, so an offset is applied to a second input of the same file
+	audioInput := 0
+	if bc.audio.mode == AudioModeCopy && bc.opts.AudioOffsetMs != 0 {
+		args = append(args, audioOffsetInputArgs(bc.opts.AudioOffsetMs, bc.opts.TrimStart, bc.opts.TrimEnd)...)
+		args = append(args, "-i", bc.inputPath)
+		audioInput = 1
+	}
+
+	// Select the first video track and the chosen audio track; a loop crossfade
+	// filters both in one graph
+	loopFade := bc.loopCrossfade()
+	if loopFade > 0 {
+		args = append(args, "-filter_complex", p.loopGraph(bc, loopFade, audioInput))
+		args = append(args, "-map", "[v]")
+		if bc.audio.mode != AudioModeNone {
+			args = append(args, "-map", "[a]")
+		}
+	} else {
+		args = append(args, "-map", "0:v:0")
+		args = append(args, bc.audio.mapArgs(audioInput)...)
+	}
 
 	// Add encoder-specific video codec options
-	args = append(args, getEncoderArgs(encoderID, settings, effectiveLevel, keyint, effectiveWidth, effectiveHeight)...)
+	args = append(args, getEncoderArgs(bc.opts.EncoderID, bc.settings, bc.level, bc.keyint, bc.width, bc.height)...)
 
-	// Add resolution if not using source
-	if !p.UseSourceRes && p.Width > 0 && p.Height > 0 {
-		args = append(args, "-vf", fmt.Sprintf("scale=%d:%d", p.Width, p.Height))
+	// Deinterlace and scale the source video
+	if filters := p.videoFilters(bc); loopFade == 0 && len(filters) > 0 {
+		args = append(args, "-vf", strings.Join(filters, ","))
+	}
+
+	// GPU frames already carry the output format from the hardware filters
+	if !bc.hw.hwFrames() {
+		args = append(args, "-pix_fmt", bc.pixFmt)
 	}
 
 	// Add framerate if not using source
@@ -210,105 +490,157 @@ func (p *Preset) buildStandardArgs(inputPath, outputPath string, settings Encodi
 	}
 
 	// CFR mode
-	if settings.CFR {
+	if bc.settings.CFR {
 		args = append(args, "-vsync", "cfr")
 	}
 
 	// Audio settings
-	args = append(args,
-		"-c:a", "aac",
-		"-b:a", fmt.Sprintf("%dk", settings.AudioBitrate),
-		"-ac", "2",
-	)
-
-	// Deinterlace filter (equivalent to HandBrake's decomb)
-	if settings.Decomb {
-		if !p.UseSourceRes && p.Width > 0 {
-			for i, arg := range args {
-				if arg == "-vf" && i+1 < len(args) {
-					args[i+1] = "yadif=mode=0:parity=-1:deint=1," + args[i+1]
-					break
-				}
-			}
-		} else {
-			args = append(args, "-vf", "yadif=mode=0:parity=-1:deint=1")
-		}
+	if filters := bc.audioFilters(); loopFade == 0 && len(filters) > 0 {
+		args = append(args, "-af", strings.Join(filters, ","))
 	}
+	args = append(args, bc.audio.codecArgs()...)
 
 	// Output format
 	args = append(args, "-f", "matroska")
-	args = append(args, outputPath)
+	args = append(args, bc.outputPath)
 
 	return args
 }
 
-// buildArgsWithBlackIntro builds FFmpeg args with black intro prepended
-func (p *Preset) buildArgsWithBlackIntro(inputPath, outputPath string, settings EncodingSettings, encoderID string, effectiveWidth, effectiveHeight int, effectiveFPS float64, effectiveLevel string, keyint int, blackDuration int) []string {
-	fpsStr := fmt.Sprintf("%.3f", effectiveFPS)
+// buildArgsWithRolls builds FFmpeg args with a pre-roll and/or post-roll around the source.
+// Rolls are black, a still image or a clip, conformed to the output size and framerate.
+func (p *Preset) buildArgsWithRolls(bc buildContext) []string {
+	fpsStr := fmt.Sprintf("%.3f", bc.fps)
 
 	// Add pre-input args for hardware encoders (must come before -i)
-	args := getPreInputArgs(encoderID)
-
-	// Build inputs: black video, silent audio, then source file
-	args = append(args,
-		"-f", "lavfi", "-i", fmt.Sprintf("color=black:s=%dx%d:d=%d:r=%s", effectiveWidth, effectiveHeight, blackDuration, fpsStr),
-		"-f", "lavfi", "-t", fmt.Sprintf("%d", blackDuration), "-i", "anullsrc=r=48000:cl=stereo",
-		"-i", inputPath,
-	)
+	args := GetPreInputArgs(bc.opts.EncoderID, bc.opts.RenderDevice)
+
+	// Build inputs: pre-roll video and audio (silence when the roll has none and the output
+	// has audio), the source file, then post-roll video and audio
+	hasAudio := bc.audio.mode != AudioModeNone
+	input := 0
+	segments := 1
+	rollFilter := ""
+	introLabels := ""
+	if bc.opts.PreRoll.active(bc.fps) {
+		filter, labels := bc.rollInputs(&args, bc.opts.PreRoll, "pre", hasAudio, &input)
+		rollFilter += filter
+		introLabels = labels
+		segments++
+	}
+	// Hardware decode args apply to the source input only
+	if bc.hw != nil {
+		args = append(args, bc.hw.hwDecodeArgs()...)
+	}
+	args = append(args, TrimArgs(bc.opts.TrimStart, bc.opts.TrimEnd)...)
+	args = append(args, "-i", bc.inputPath)
+	srcInput := input
+	input++
+	outroLabels := ""
+	if bc.opts.PostRoll.active(bc.fps) {
+		filter, labels := bc.rollInputs(&args, bc.opts.PostRoll, "post", hasAudio, &input)
+		rollFilter += filter
+		outroLabels = labels
+		segments++
+	}
 
 	// Build filter_complex
-	// [0:v] = black video, [1:a] = silent audio, [2:v] = source video, [2:a] = source audio
+	// e.g. [0:v] = black video, [1:a] = silent audio, [2:v] = source video, [2:a:N] = source audio,
+	// [3:v] = post-roll clip → [postv], [3:a:0] = its audio → [posta]
+	srcVideo := fmt.Sprintf("[%d:v]", srcInput)
 	videoFilter := ""
-	// Apply scale to source video if needed
-	if !p.UseSourceRes && p.Width > 0 && p.Height > 0 {
-		videoFilter = fmt.Sprintf("[2:v]scale=%d:%d", p.Width, p.Height)
-		if settings.Decomb {
-			videoFilter += ",yadif=mode=0:parity=-1:deint=1"
-		}
-		videoFilter += "[srcv];"
-	} else if settings.Decomb {
-		videoFilter = "[2:v]yadif=mode=0:parity=-1:deint=1[srcv];"
+	filters := p.videoFilters(bc)
+	if bc.hw.hwFrames() {
+		// The pre/post-roll frames live in system memory, so download GPU frames before concat
+		filters = append(filters, "hwdownload", "format="+bc.pixFmt)
+	}
+	if len(filters) > 0 {
+		// Source video was filtered → use [srcv]
+		videoFilter = srcVideo + strings.Join(filters, ",") + "[srcv];"
+		srcVideo = "[srcv]"
+	}
+
+	// Offset, loudness and fade filters apply to the source audio only, not to the rolls
+	srcAudio := fmt.Sprintf("[%d:a:%d]", srcInput, bc.audio.track)
+	audioFilter := ""
+	if filters := bc.audioFilters(); hasAudio && len(filters) > 0 {
+		audioFilter = srcAudio + strings.Join(filters, ",") + "[srca];"
+		srcAudio = "[srca]"
 	}
 
 	var filterComplex string
-	if videoFilter != "" {
-		// Source video was filtered → use [srcv]
-		filterComplex = fmt.Sprintf("%s[0:v][1:a][srcv][2:a]concat=n=2:v=1:a=1[v][a]", videoFilter)
+	if hasAudio {
+		filterComplex = fmt.Sprintf("%s%s%s%s%s%s%sconcat=n=%d:v=1:a=1[v][a]",
+			rollFilter, videoFilter, audioFilter, introLabels, srcVideo, srcAudio, outroLabels, segments)
 	} else {
-		// No filter on source → use [2:v] directly
-		filterComplex = "[0:v][1:a][2:v][2:a]concat=n=2:v=1:a=1[v][a]"
+		filterComplex = fmt.Sprintf("%s%s%s%s%sconcat=n=%d:v=1:a=0[v]", rollFilter, videoFilter, introLabels, srcVideo, outroLabels, segments)
 	}
 
 	args = append(args, "-filter_complex", filterComplex)
-	args = append(args, "-map", "[v]", "-map", "[a]")
+	args = append(args, "-map", "[v]")
+	if hasAudio {
+		args = append(args, "-map", "[a]")
+	}
 
 	// Add encoder-specific video codec options
-	args = append(args, getEncoderArgs(encoderID, settings, effectiveLevel, keyint, effectiveWidth, effectiveHeight)...)
+	args = append(args, getEncoderArgs(bc.opts.EncoderID, bc.settings, bc.level, bc.keyint, bc.width, bc.height)...)
 
 	// Add framerate if not using source
 	if !p.UseSourceFPS && p.FPS > 0 {
 		args = append(args, "-r", fpsStr)
 	}
 
+	// Concat output lives in system memory, so always set the output format
+	args = append(args, "-pix_fmt", bc.pixFmt)
+
 	// CFR mode
-	if settings.CFR {
+	if bc.settings.CFR {
 		args = append(args, "-vsync", "cfr")
 	}
 
 	// Audio settings
-	args = append(args,
-		"-c:a", "aac",
-		"-b:a", fmt.Sprintf("%dk", settings.AudioBitrate),
-		"-ac", "2",
-	)
+	args = append(args, bc.audio.codecArgs()...)
 
 	// Output format
 	args = append(args, "-f", "matroska")
-	args = append(args, outputPath)
+	args = append(args, bc.outputPath)
 
 	return args
 }
 
+// videoFilters returns the deinterlace and scale filters for the source video,
+// running on the GPU when frames are kept in GPU memory
+func (p *Preset) videoFilters(bc buildContext) []string {
+	scaleWidth, scaleHeight := 0, 0
+	if !p.UseSourceRes && p.Width > 0 && p.Height > 0 {
+		scaleWidth, scaleHeight = p.Width, p.Height
+	}
+
+	if bc.hw.hwFrames() {
+		return hwVideoFilters(bc.hw, bc.settings.Decomb, scaleWidth, scaleHeight, bc.pixFmt)
+	}
+
+	var filters []string
+	// Deinterlace filter (equivalent to HandBrake's decomb)
+	if bc.settings.Decomb {
+		filters = append(filters, "yadif=mode=0:parity=-1:deint=1")
+	}
+	if scaleWidth > 0 {
+		filters = append(filters, fmt.Sprintf("scale=%d:%d", scaleWidth, scaleHeight))
+	}
+	// HDR to SDR or SDR color space conversion
+	if bc.toneMap != "" {
+		filters = append(filters, toneMapFilters(bc.source, bc.toneMap, bc.bitDepth)...)
+	} else if bc.convert709 {
+		filters = append(filters, convertColorFilters(sourceColorTags(bc.source), bt709Limited, bc.bitDepth)...)
+	}
+	filters = append(filters, bc.opts.Fades.videoFadeFilters(bc.sourceDuration())...)
+	if bc.opts.Overlay != nil {
+		filters = append(filters, bc.opts.Overlay.filters(bc.fps)...)
+	}
+	return filters
+}
+
 // GetPresetInfo returns a human-readable description of the preset
 func (p *Preset) GetPresetInfo() string {
 	if p.UseSourceRes && p.UseSourceFPS {
@@ -317,13 +649,23 @@ func (p *Preset) GetPresetInfo() string {
 	return fmt.Sprintf("%s @ %sfps, HEVC 인코딩", p.Resolution, p.Framerate)
 }
 
-// getPreInputArgs returns FFmpeg arguments that must appear before -i for hardware encoders
-func getPreInputArgs(encoderID string) []string {
+// defaultRenderDevice is the DRM render node used when no device is selected
+const defaultRenderDevice = "/dev/dri/renderD128"
+
+// GetPreInputArgs returns FFmpeg arguments that must appear before -i for hardware encoders.
+// device selects the DRM render node for VAAPI/QSV ("" = default).
+func GetPreInputArgs(encoderID string, device string) []string {
 	switch encoderID {
 	case "hevc_qsv":
+		if device != "" {
+			return []string{"-init_hw_device", "qsv=hw:hw_any,child_device=" + device, "-filter_hw_device", "hw"}
+		}
 		return []string{"-init_hw_device", "qsv=hw", "-filter_hw_device", "hw"}
 	case "hevc_vaapi":
-		return []string{"-init_hw_device", "vaapi=hw:/dev/dri/renderD128", "-filter_hw_device", "hw"}
+		if device == "" {
+			device = defaultRenderDevice
+		}
+		return []string{"-init_hw_device", "vaapi=hw:" + device, "-filter_hw_device", "hw"}
 	default:
 		return nil
 	}
@@ -334,34 +676,32 @@ func getEncoderArgs(encoderID string, settings EncodingSettings, level string, k
 	switch encoderID {
 	case "hevc_videotoolbox":
 		// Apple VideoToolbox (macOS)
-		// Calculate bitrate based on resolution to match libx265 CRF quality
-		// Base: ~3 Mbps for 1080p, scales linearly with pixel count
-		pixels := width * height
-		if pixels == 0 {
-			pixels = 1920 * 1080 // default to 1080p
+		// Bitrate is estimated from resolution to match libx265 CRF quality; the
+		// constant quality mode (-q:v) is Apple Silicon only and cannot be capped
+		bitrateK := estimateBitrateK(settings, width, height)
+		args := []string{
+			"-c:v", "hevc_videotoolbox",
+			"-b:v", fmt.Sprintf("%dk", bitrateK),
 		}
-		baseBitrateK := float64(pixels) / float64(1920*1080) * 3000
-		// Quality adjustment: each CRF point changes bitrate by ~12%
-		qualityFactor := math.Pow(1.12, float64(22-settings.Quality))
-		bitrateK := int(baseBitrateK * qualityFactor)
-		if bitrateK < 500 {
-			bitrateK = 500
+		if settings.MaxBitrate > 0 {
+			args = append(args, "-maxrate", fmt.Sprintf("%dk", settings.MaxBitrate))
 		}
-		if bitrateK > 50000 {
-			bitrateK = 50000
+		// -profile:v is only passed for 10-bit; main is the default and the
+		// explicit main profile produced 0KB files
+		if settings.EncoderProfile == "main10" {
+			args = append(args, "-profile:v", "main10")
 		}
-		return []string{
-			"-c:v", "hevc_videotoolbox",
-			"-b:v", fmt.Sprintf("%dk", bitrateK),
+		args = append(args,
 			"-tag:v", "hvc1",
 			"-allow_sw", "1",
-		}
+		)
+		return append(args, colorArgs(settings)...)
 
 	case "hevc_nvenc":
 		// NVIDIA NVENC
 		// CQ mode with quality value (0-51, lower is better)
 		// Note: B-frames removed for compatibility with older NVIDIA GPUs (e.g., Quadro P1000)
-		return []string{
+		args := []string{
 			"-c:v", "hevc_nvenc",
 			"-rc", "vbr",
 			"-cq", fmt.Sprintf("%d", settings.Quality),
@@ -370,52 +710,87 @@ func getEncoderArgs(encoderID string, settings EncodingSettings, level string, k
 			"-level:v", level,
 			"-g", fmt.Sprintf("%d", keyint),
 		}
+		args = append(args, vbvArgs(settings)...)
+		return append(args, colorArgs(settings)...)
 
 	case "hevc_qsv":
 		// Intel QuickSync
 		// Use CQP mode for maximum compatibility (ICQ/global_quality not supported on all GPUs)
 		// Use low_power mode for newer Intel GPUs (Iris Xe etc.) that only support VDENC path
+		// main10 is only requested when the capability probe found 10-bit support
 		profile := settings.EncoderProfile
-		if profile == "main10" {
-			profile = "main" // Fallback for compatibility
-		}
-		return []string{
+		args := []string{
 			"-c:v", "hevc_qsv",
 			"-low_power", "1",
-			"-rc:v", "CQP",
-			"-qp", fmt.Sprintf("%d", settings.Quality),
+		}
+		if settings.MaxBitrate > 0 {
+			// CQP ignores rate limits; global_quality with a maxrate selects QVBR, which
+			// keeps the quality value and only lowers it where the cap would be exceeded
+			args = append(args,
+				"-global_quality", fmt.Sprintf("%d", settings.Quality),
+				"-b:v", fmt.Sprintf("%dk", estimateBitrateK(settings, width, height)),
+			)
+			args = append(args, vbvArgs(settings)...)
+		} else {
+			args = append(args, "-rc:v", "CQP", "-qp", fmt.Sprintf("%d", settings.Quality))
+		}
+		args = append(args,
 			"-preset", mapQsvPreset(settings.EncoderPreset),
 			"-profile:v", profile,
 			"-g", fmt.Sprintf("%d", keyint),
-		}
+		)
+		return append(args, colorArgs(settings)...)
 
 	case "hevc_amf":
 		// AMD AMF
-		// Note: Older AMD GPUs may not support main10 profile
+		// Note: Older AMD GPUs may not support main10 profile; it is only requested
+		// when the capability probe found 10-bit support
 		profile := settings.EncoderProfile
-		if profile == "main10" {
-			profile = "main" // Fallback for compatibility
+		args := []string{"-c:v", "hevc_amf"}
+		if settings.MaxBitrate > 0 {
+			// CQP ignores rate limits; QVBR keeps the quality level under the peak cap
+			args = append(args,
+				"-rc", "qvbr",
+				"-qvbr_quality_level", fmt.Sprintf("%d", settings.Quality),
+				"-b:v", fmt.Sprintf("%dk", estimateBitrateK(settings, width, height)),
+			)
+			args = append(args, vbvArgs(settings)...)
+		} else {
+			args = append(args,
+				"-rc", "cqp",
+				"-qp_i", fmt.Sprintf("%d", settings.Quality),
+				"-qp_p", fmt.Sprintf("%d", settings.Quality),
+			)
 		}
-		return []string{
-			"-c:v", "hevc_amf",
-			"-rc", "cqp",
-			"-qp_i", fmt.Sprintf("%d", settings.Quality),
-			"-qp_p", fmt.Sprintf("%d", settings.Quality),
+		args = append(args,
 			"-quality", mapAmfQuality(settings.EncoderPreset),
 			"-profile:v", profile,
 			"-level:v", level,
 			"-gops_per_idr", "1",
-		}
+		)
+		return append(args, colorArgs(settings)...)
 
 	case "hevc_vaapi":
 		// Linux VAAPI
-		return []string{
-			"-c:v", "hevc_vaapi",
-			"-qp", fmt.Sprintf("%d", settings.Quality),
+		args := []string{"-c:v", "hevc_vaapi"}
+		if settings.MaxBitrate > 0 {
+			// CQP ignores rate limits. With global_quality and a bitrate, FFmpeg's automatic
+			// rate control picks QVBR, and falls back to VBR with the estimated target only
+			// on drivers without QVBR (e.g. Mesa), where a quality mode cannot be capped
+			args = append(args,
+				"-global_quality", fmt.Sprintf("%d", settings.Quality),
+				"-b:v", fmt.Sprintf("%dk", estimateBitrateK(settings, width, height)),
+			)
+			args = append(args, vbvArgs(settings)...)
+		} else {
+			args = append(args, "-qp", fmt.Sprintf("%d", settings.Quality))
+		}
+		args = append(args,
 			"-profile:v", settings.EncoderProfile,
 			"-level:v", level,
 			"-g", fmt.Sprintf("%d", keyint),
-		}
+		)
+		return append(args, colorArgs(settings)...)
 
 	default:
 		// libx265 (software)
@@ -423,7 +798,20 @@ func getEncoderArgs(encoderID string, settings EncodingSettings, level string, k
 			"keyint=%d:min-keyint=%d:open-gop=0:scenecut=0:repeat-headers=1:ref=4:bframes=3:hrd=1",
 			keyint, keyint,
 		)
-		return []string{
+		if settings.MaxBitrate > 0 {
+			x265Params += fmt.Sprintf(":vbv-maxrate=%d:vbv-bufsize=%d", settings.MaxBitrate, settings.BufSize)
+		}
+		// HDR10 SEI; the other encoders write mastering metadata from frame side data where supported
+		if settings.ColorTransfer == "smpte2084" {
+			x265Params += ":hdr10=1:hdr10-opt=1"
+			if settings.MasterDisplay != "" {
+				x265Params += ":master-display=" + settings.MasterDisplay
+			}
+			if settings.MaxCLL != "" {
+				x265Params += ":max-cll=" + settings.MaxCLL
+			}
+		}
+		args := []string{
 			"-c:v", "libx265",
 			"-crf", fmt.Sprintf("%d", settings.Quality),
 			"-preset", settings.EncoderPreset,
@@ -432,6 +820,43 @@ func getEncoderArgs(encoderID string, settings EncodingSettings, level string, k
 			"-level:v", level,
 			"-x265-params", x265Params,
 		}
+		return append(args, colorArgs(settings)...)
+	}
+}
+
+// estimateBitrateK estimates a target bitrate (kbps) that matches libx265 CRF quality,
+// for encoders driven by bitrate and as the target of capped quality modes (QVBR)
+func estimateBitrateK(settings EncodingSettings, width int, height int) int {
+	// Base: ~3 Mbps for 1080p, scales linearly with pixel count
+	pixels := width * height
+	if pixels == 0 {
+		pixels = 1920 * 1080 // default to 1080p
+	}
+	baseBitrateK := float64(pixels) / float64(1920*1080) * 3000
+	// Quality adjustment: each CRF point changes bitrate by ~12%
+	qualityFactor := math.Pow(1.12, float64(22-settings.Quality))
+	bitrateK := int(baseBitrateK * qualityFactor)
+	if bitrateK < 500 {
+		bitrateK = 500
+	}
+	if bitrateK > 50000 {
+		bitrateK = 50000
+	}
+	// Never target more than the VBV max bitrate
+	if settings.MaxBitrate > 0 && bitrateK > settings.MaxBitrate {
+		bitrateK = settings.MaxBitrate
+	}
+	return bitrateK
+}
+
+// vbvArgs returns the generic -maxrate/-bufsize arguments for the VBV caps
+func vbvArgs(settings EncodingSettings) []string {
+	if settings.MaxBitrate <= 0 {
+		return nil
+	}
+	return []string{
+		"-maxrate", fmt.Sprintf("%dk", settings.MaxBitrate),
+		"-bufsize", fmt.Sprintf("%dk", settings.BufSize),
 	}
 }
 
@@ -0,0 +1,222 @@
+package preset
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testSource is a 10 s 1080p30 source with one stereo AAC track
+var testSource = &FileInfo{
+	Width:       1920,
+	Height:      1080,
+	Framerate:   30,
+	Duration:    10,
+	Codec:       "h264",
+	AudioTracks: []AudioTrack{{Codec: "aac", Channels: 2}},
+}
+
+// argValue returns the value following the first occurrence of flag ("" = not present)
+func argValue(args []string, flag string) string {
+	if i := slices.Index(args, flag); i >= 0 && i+1 < len(args) {
+		return args[i+1]
+	}
+	return ""
+}
+
+// sourceInputIndex returns the position of "-i <input>" in args
+func sourceInputIndex(t *testing.T, args []string, input string) int {
+	t.Helper()
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "-i" && args[i+1] == input {
+			return i
+		}
+	}
+	t.Fatalf("no -i %s in %v", input, args)
+	return -1
+}
+
+func TestToFFmpegArgsWithOptions(t *testing.T) {
+	p := GetPresetByName("HEVC 1080p|30p")
+	if p == nil {
+		t.Fatal("preset HEVC 1080p|30p not found")
+	}
+	blackRoll := Roll{Kind: RollBlack, Duration: RollDuration{Frames: 30}}
+
+	tests := []struct {
+		name     string
+		opts     EncodeOptions
+		trim     []string // input options right before the source -i
+		graph    []string // substrings of -filter_complex (nil = no filter graph)
+		notGraph []string // substrings -filter_complex must not contain
+	}{
+		{
+			name: "plain",
+			opts: EncodeOptions{EncoderID: "libx265"},
+		},
+		{
+			name: "in and out points",
+			opts: EncodeOptions{EncoderID: "libx265", TrimStart: 2, TrimEnd: 8},
+			trim: []string{"-ss", "2.000", "-t", "6.000"},
+		},
+		{
+			name: "pre-roll with trims",
+			opts: EncodeOptions{EncoderID: "libx265", TrimStart: 2, TrimEnd: 8, PreRoll: blackRoll},
+			trim: []string{"-ss", "2.000", "-t", "6.000"},
+			graph: []string{
+				"[0:v][1:a][srcv][2:a:0]concat=n=2:v=1:a=1[v][a]",
+			},
+		},
+		{
+			name: "pre-roll and post-roll",
+			opts: EncodeOptions{EncoderID: "libx265", PreRoll: blackRoll, PostRoll: blackRoll},
+			graph: []string{
+				"concat=n=3:v=1:a=1[v][a]",
+			},
+		},
+		{
+			name: "loop crossfade over the trimmed part",
+			opts: EncodeOptions{EncoderID: "libx265", TrimStart: 2, TrimEnd: 8, LoopCrossfade: 1},
+			trim: []string{"-ss", "2.000", "-t", "6.000"},
+			graph: []string{
+				"split=3[lbody][ltail][lhead]",
+				"[lbody]trim=start=1.000000:end=5.000000",
+				"[ltail]trim=start=5.000000",
+				"xfade=transition=fade:duration=1.000000:offset=0[seam]",
+				"[atail][ahead]acrossfade=d=1.000000[aseam]",
+			},
+		},
+		{
+			name: "loop crossfade too long for the source",
+			opts: EncodeOptions{EncoderID: "libx265", LoopCrossfade: 6},
+		},
+		{
+			// The encoder refuses this combination before the batch starts
+			name:     "rolls take precedence over the loop crossfade",
+			opts:     EncodeOptions{EncoderID: "libx265", PreRoll: blackRoll, LoopCrossfade: 1},
+			graph:    []string{"concat=n=2:v=1:a=1[v][a]"},
+			notGraph: []string{"xfade"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := p.ToFFmpegArgsWithOptions("in.mp4", "out.mkv", testSource, tt.opts)
+
+			if args[len(args)-1] != "out.mkv" || argValue(args, "-f") == "" {
+				t.Errorf("output not last: %v", args)
+			}
+
+			input := sourceInputIndex(t, args, "in.mp4")
+			if len(tt.trim) > 0 {
+				if input < len(tt.trim) || !slices.Equal(args[input-len(tt.trim):input], tt.trim) {
+					t.Errorf("input options before -i = %v, want %v", args[max(0, input-len(tt.trim)):input], tt.trim)
+				}
+			} else if slices.Contains(args[:input], "-ss") {
+				t.Errorf("unexpected seek before the source: %v", args[:input])
+			}
+
+			graph := argValue(args, "-filter_complex")
+			if tt.graph == nil {
+				if graph != "" {
+					t.Errorf("unexpected -filter_complex %q", graph)
+				}
+				if argValue(args, "-vf") == "" {
+					t.Errorf("no -vf for the source filters: %v", args)
+				}
+				return
+			}
+			for _, want := range tt.graph {
+				if !strings.Contains(graph, want) {
+					t.Errorf("-filter_complex %q does not contain %q", graph, want)
+				}
+			}
+			for _, unwanted := range tt.notGraph {
+				if strings.Contains(graph, unwanted) {
+					t.Errorf("-filter_complex %q contains %q", graph, unwanted)
+				}
+			}
+			if argValue(args, "-vf") != "" || argValue(args, "-af") != "" {
+				t.Errorf("-vf/-af combined with -filter_complex: %v", args)
+			}
+			if argValue(args, "-map") != "[v]" {
+				t.Errorf("-map = %q, want [v]", argValue(args, "-map"))
+			}
+		})
+	}
+}
+
+func TestTrimmedDuration(t *testing.T) {
+	tests := []struct {
+		duration, start, end, want float64
+	}{
+		{10, 0, 0, 10},
+		{10, 2, 0, 8},
+		{10, 2, 8, 6},
+		{10, 0, 12, 10}, // out point past the end
+		{10, 12, 0, 0},  // in point past the end
+		{0, 2, 8, 0},    // unknown duration stays unknown
+	}
+
+	for _, tt := range tests {
+		if got := TrimmedDuration(tt.duration, tt.start, tt.end); got != tt.want {
+			t.Errorf("TrimmedDuration(%g, %g, %g) = %g, want %g", tt.duration, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestVBVFor(t *testing.T) {
+	tests := []struct {
+		preset           string
+		wantMax, wantBuf int
+	}{
+		{"HEVC 4K|60p", 35000, 40000},    // level 5.1 CPB
+		{"HEVC 4K|30p", 25000, 25000},    // level 5.0 CPB
+		{"HEVC 1080p|30p", 10000, 20000}, // level 4.1 CPB
+	}
+	for _, tt := range tests {
+		p := GetPresetByName(tt.preset)
+		if p == nil {
+			t.Fatalf("preset %s not found", tt.preset)
+		}
+		if maxBitrate, bufSize := p.VBVFor(testSource); maxBitrate != tt.wantMax || bufSize != tt.wantBuf {
+			t.Errorf("%s: VBVFor() = %d, %d, want %d, %d", tt.preset, maxBitrate, bufSize, tt.wantMax, tt.wantBuf)
+		}
+	}
+}
+
+func TestGetEncoderArgsRateControl(t *testing.T) {
+	capped := DefaultSettings()
+	capped.Quality = 24
+	capped.MaxBitrate, capped.BufSize = 15000, 20000
+	uncapped := DefaultSettings()
+	uncapped.Quality = 24
+
+	tests := []struct {
+		encoderID string
+		settings  EncodingSettings
+		want      map[string]string // flag -> value
+		notFlags  []string
+	}{
+		{"hevc_qsv", capped, map[string]string{"-global_quality": "24", "-maxrate": "15000k", "-bufsize": "20000k"}, []string{"-rc:v", "-qp"}},
+		{"hevc_qsv", uncapped, map[string]string{"-rc:v": "CQP", "-qp": "24"}, []string{"-maxrate"}},
+		{"hevc_amf", capped, map[string]string{"-rc": "qvbr", "-qvbr_quality_level": "24", "-maxrate": "15000k", "-bufsize": "20000k"}, []string{"-qp_p"}},
+		{"hevc_amf", uncapped, map[string]string{"-rc": "cqp", "-qp_p": "24"}, []string{"-maxrate"}},
+		{"hevc_vaapi", capped, map[string]string{"-global_quality": "24", "-maxrate": "15000k", "-bufsize": "20000k"}, []string{"-rc_mode", "-qp"}},
+		{"hevc_vaapi", uncapped, map[string]string{"-qp": "24"}, []string{"-maxrate"}},
+		{"hevc_nvenc", capped, map[string]string{"-rc": "vbr", "-cq": "24", "-maxrate": "15000k", "-bufsize": "20000k"}, nil},
+	}
+	for _, tt := range tests {
+		args := getEncoderArgs(tt.encoderID, tt.settings, "4.1", 30, 1920, 1080)
+		for flag, value := range tt.want {
+			if got := argValue(args, flag); got != value {
+				t.Errorf("%s (maxrate %d): %s = %q, want %q", tt.encoderID, tt.settings.MaxBitrate, flag, got, value)
+			}
+		}
+		for _, flag := range tt.notFlags {
+			if slices.Contains(args, flag) {
+				t.Errorf("%s (maxrate %d): unexpected %s in %v", tt.encoderID, tt.settings.MaxBitrate, flag, args)
+			}
+		}
+	}
+}
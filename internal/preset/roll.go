@@ -0,0 +1,157 @@
+package preset
+
+import (
+	"fmt"
+	"math"
+)
+
+// Pre/post-roll kinds
+const (
+	RollBlack = "black" // solid black with silence
+	RollImage = "image" // still image (slate, logo) held for the roll duration, with silence
+	RollClip  = "clip"  // video clip (countdown, logo animation) played in full
+)
+
+// RollDuration is the length of a generated segment, either an exact
+// frame count or fractional seconds rounded to whole frames
+type RollDuration struct {
+	Frames  int     `json:"frames"`  // frame count (takes precedence over Seconds)
+	Seconds float64 `json:"seconds"` // seconds, e.g. 0.4
+}
+
+// FrameCount returns the number of frames the segment spans at fps
+// (0 when the framerate is unknown, since no frames can be generated)
+func (d RollDuration) FrameCount(fps float64) int {
+	if fps <= 0 {
+		return 0
+	}
+	if d.Frames > 0 {
+		return d.Frames
+	}
+	if d.Seconds <= 0 {
+		return 0
+	}
+	return int(math.Round(d.Seconds * fps))
+}
+
+// Roll is a segment inserted before (pre-roll) or after (post-roll) the source
+type Roll struct {
+	Kind     string       `json:"kind"`     // RollBlack, RollImage or RollClip ("" = black)
+	Path     string       `json:"path"`     // image or clip file
+	Duration RollDuration `json:"duration"` // length of black and image rolls (zero value = disabled)
+
+	// Probed clip properties, filled in by the encoder
+	ClipDuration float64 `json:"clipDuration"` // seconds
+	ClipHasAudio bool    `json:"clipHasAudio"`
+}
+
+// FrameCount returns the number of frames the roll adds at fps (0 = no roll).
+// Clip frame counts are estimated from the probed duration.
+func (r Roll) FrameCount(fps float64) int {
+	if r.Kind == RollClip {
+		if r.Path == "" || fps <= 0 {
+			return 0
+		}
+		return int(math.Round(r.ClipDuration * fps))
+	}
+	if r.Kind == RollImage && r.Path == "" {
+		return 0
+	}
+	return r.Duration.FrameCount(fps)
+}
+
+// active reports whether the roll adds a segment at fps
+func (r Roll) active(fps float64) bool {
+	if r.Kind == RollClip {
+		return r.Path != "" && fps > 0
+	}
+	return r.FrameCount(fps) > 0
+}
+
+// rollInputs appends the inputs of a roll segment, starting at input index *input.
+// It returns the filters that conform the segment to the output and its concat labels.
+// name ("pre" or "post") keeps the filter labels of the two rolls apart.
+func (bc *buildContext) rollInputs(args *[]string, r Roll, name string, hasAudio bool, input *int) (filter string, labels string) {
+	fps := frameRateArg(bc.fps)
+	frames := r.FrameCount(bc.fps)
+	videoInput := *input
+
+	switch r.Kind {
+	case RollClip:
+		*args = append(*args, "-i", r.Path)
+		filter = fmt.Sprintf("[%d:v]%s[%sv];", videoInput, bc.conformFilters(fps), name)
+		labels = fmt.Sprintf("[%sv]", name)
+	case RollImage:
+		// The still image is looped at the output framerate and cut at an exact frame count
+		*args = append(*args, "-loop", "1", "-framerate", fps, "-i", r.Path)
+		filter = fmt.Sprintf("[%d:v]%s,trim=end_frame=%d[%sv];", videoInput, bc.conformFilters(fps), frames, name)
+		labels = fmt.Sprintf("[%sv]", name)
+	default:
+		// trim cuts the black source at an exact frame count
+		*args = append(*args,
+			"-f", "lavfi", "-i", fmt.Sprintf("color=black:s=%dx%d:r=%s,trim=end_frame=%d", bc.width, bc.height, fps, frames),
+		)
+		labels = fmt.Sprintf("[%d:v]", videoInput)
+	}
+	*input++
+
+	if !hasAudio {
+		return filter, labels
+	}
+
+	// Clip audio is conformed to the output layout and padded or cut to the clip length
+	// so the source stays in sync; everything else gets silence of the same length
+	if r.Kind == RollClip && r.ClipHasAudio {
+		filter += fmt.Sprintf("[%d:a:0]aresample=48000,aformat=channel_layouts=%s,apad,atrim=end=%.6f[%sa];",
+			videoInput, bc.audio.layout, r.ClipDuration, name)
+		return filter, labels + fmt.Sprintf("[%sa]", name)
+	}
+	seconds := float64(frames) / bc.fps
+	if r.Kind == RollClip {
+		seconds = r.ClipDuration
+	}
+	*args = append(*args,
+		"-f", "lavfi", "-t", fmt.Sprintf("%.6f", seconds), "-i", "anullsrc=r=48000:cl="+bc.audio.layout,
+	)
+	labels += fmt.Sprintf("[%d:a]", *input)
+	*input++
+	return filter, labels
+}
+
+// conformFilters scales and pads a pre/post-roll picture to the output size and framerate,
+// converting RGB images with the output matrix
+func (bc *buildContext) conformFilters(fps string) string {
+	return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease:out_color_matrix=%s:out_range=%s,pad=%d:%d:(ow-iw)/2:(oh-ih)/2:black,setsar=1,fps=%s,format=%s",
+		bc.width, bc.height, swscaleMatrix(bc.settings.ColorSpace), swscaleRange(bc.settings.ColorRange),
+		bc.width, bc.height, fps, planarFormat(bc.bitDepth))
+}
+
+// swscaleMatrix returns the scale filter matrix name for an FFmpeg color space
+func swscaleMatrix(space string) string {
+	switch space {
+	case "bt2020nc", "bt2020c":
+		return "bt2020"
+	case "smpte170m", "bt470bg":
+		return "bt601"
+	default:
+		return "bt709"
+	}
+}
+
+// swscaleRange returns the scale filter range name for an FFmpeg color range
+func swscaleRange(colorRange string) string {
+	if colorRange == "pc" {
+		return "full"
+	}
+	return "limited"
+}
+
+// frameRateArg formats a framerate for generated sources, using the exact
+// 1001-based fraction for NTSC rates so generated frames line up with the source
+func frameRateArg(fps float64) string {
+	nominal := math.Round(fps * 1.001)
+	if math.Abs(fps-nominal*1000/1001) < 0.001 && math.Abs(fps-nominal) > 0.001 {
+		return fmt.Sprintf("%.0f000/1001", nominal)
+	}
+	return fmt.Sprintf("%.3f", fps)
+}
@@ -2,15 +2,45 @@ package preset
 
 // Preset represents a video encoding preset
 type Preset struct {
-	Name         string  `json:"name"`
-	Resolution   string  `json:"resolution"`   // "4K", "1080p", or "source"
-	Framerate    string  `json:"framerate"`    // "60", "30", "29.97", "24", "23.976", or "source"
-	Width        int     `json:"width"`        // 0 = use source
-	Height       int     `json:"height"`       // 0 = use source
-	Level        string  `json:"level"`        // "5.1", "5.0", "4.1", "auto"
-	FPS          float64 `json:"fps"`          // numeric framerate value
-	UseSourceFPS bool    `json:"useSourceFps"` // true = use source framerate
-	UseSourceRes bool    `json:"useSourceRes"` // true = use source resolution
+	Name              string       `json:"name"`
+	Resolution        string       `json:"resolution"`        // "4K", "1080p", or "source"
+	Framerate         string       `json:"framerate"`         // "60", "30", "29.97", "24", "23.976", or "source"
+	Width             int          `json:"width"`             // 0 = use source
+	Height            int          `json:"height"`            // 0 = use source
+	Level             string       `json:"level"`             // "5.1", "5.0", "4.1", "auto"
+	FPS               float64      `json:"fps"`               // numeric framerate value
+	UseSourceFPS      bool         `json:"useSourceFps"`      // true = use source framerate
+	UseSourceRes      bool         `json:"useSourceRes"`      // true = use source resolution
+	BitDepth          int          `json:"bitDepth"`          // 8 or 10 (0 = 8-bit)
+	UseSourceBitDepth bool         `json:"useSourceBitDepth"` // true = 10-bit output for 10-bit sources
+	MaxBitrate        int          `json:"maxBitrate"`        // VBV max bitrate in kbps (0 = derive from level)
+	BufSize           int          `json:"bufSize"`           // VBV buffer size in kbit (0 = level CPB size)
+	ToneMap           string       `json:"toneMap"`           // HDR to SDR algorithm: "hable", "mobius", "reinhard" ("" = hable)
+	PreserveHDR       bool         `json:"preserveHdr"`       // true = keep HDR10/HLG instead of tone mapping
+	ForceBT709        bool         `json:"forceBt709"`        // true = convert and tag SDR output as BT.709 limited range
+	Audio             AudioOptions `json:"audio"`             // audio mode, layout and track (zero value = 160k stereo AAC)
+}
+
+// EncodeOptions contains per-batch options applied on top of a preset
+type EncodeOptions struct {
+	EncoderID     string        // FFmpeg encoder name (e.g., "libx265", "hevc_nvenc")
+	RenderDevice  string        // DRM render node for VAAPI/QSV ("" = default)
+	Quality       int           // CRF value (0 = use default)
+	PreRoll       Roll          // Segment before the source: black, image or clip (zero value = disabled)
+	PostRoll      Roll          // Segment after the source (zero value = disabled)
+	Fades         FadeOptions   // Fade in/out of the source segment (zero value = no fades)
+	HWDecode      bool          // Decode and scale on the encoder's GPU when the source codec allows
+	BitDepth      int           // Output bit depth override, 8 or 10 (0 = use preset)
+	HDRMode       string        // HDR handling override, HDRModeToneMap or HDRModePreserve ("" = use preset)
+	ToneMap       string        // Tonemap algorithm override ("" = use preset)
+	Audio         *AudioOptions // Audio options override (nil = use preset)
+	Loudnorm      *Loudnorm     // Two-pass loudness normalization (nil = disabled)
+	AudioOffsetMs int           // Per-file audio shift in milliseconds (+ = audio later)
+	TrimStart     float64       // Per-file in point in source seconds, including sync trims (0 = start)
+	TrimEnd       float64       // Per-file out point in source seconds (0 = end)
+	LoopCrossfade float64       // Crossfade the tail into the head for a seamless loop, in seconds (0 = off, not combined with rolls)
+	Overlay       *Overlay      // Burned-in calibration text (nil = clean output)
+	InputFormat   string        // Forced input format, e.g. "lavfi" for generated sources ("" = probe)
 }
 
 // EncodingSettings contains the common encoding settings for all presets
@@ -28,6 +58,14 @@ type EncodingSettings struct {
 	TurboFirstPass bool   `json:"turboFirstPass"` // true
 	Decomb         bool   `json:"decomb"`         // true
 	CFR            bool   `json:"cfr"`            // true (constant framerate)
+	MaxBitrate     int    `json:"maxBitrate"`     // VBV max bitrate in kbps (0 = unconstrained)
+	BufSize        int    `json:"bufSize"`        // VBV buffer size in kbps
+	ColorPrimaries string `json:"colorPrimaries"` // output color tags ("" = untagged)
+	ColorTransfer  string `json:"colorTransfer"`  // e.g., "bt709", "smpte2084"
+	ColorSpace     string `json:"colorSpace"`     // e.g., "bt709", "bt2020nc"
+	ColorRange     string `json:"colorRange"`     // "tv" or "pc"
+	MasterDisplay  string `json:"masterDisplay"`  // HDR10 mastering display (x265 syntax)
+	MaxCLL         string `json:"maxCll"`         // HDR10 content light level, e.g., "1000,400"
 }
 
 // QualityLevel represents a selectable quality option